@@ -9,7 +9,7 @@ type ArrayReader interface {
 type ArrayBuilder struct{}
 
 func (b ArrayBuilder) Init(size int) interface{} {
-	return make([]interface{}, size, size)
+	return make([]interface{}, 0, size)
 }
 
 func (b ArrayBuilder) Add(a interface{}, item interface{}) interface{} {
@@ -21,3 +21,19 @@ func (b ArrayBuilder) Add(a interface{}, item interface{}) interface{} {
 func (b ArrayBuilder) Complete(a interface{}) interface{} {
 	return a
 }
+
+// arrayChecker is implemented by array readers that can tell an array cannot be
+// completed, like a cmap with a key left without a value
+type arrayChecker interface {
+	check(a interface{}) error
+}
+
+// completeArray completes the array a, or returns the error the reader reports
+func completeArray(r ArrayReader, a interface{}) (interface{}, error) {
+	if c, ok := r.(arrayChecker); ok {
+		if err := c.check(a); err != nil {
+			return nil, err
+		}
+	}
+	return r.Complete(a), nil
+}
@@ -4,22 +4,30 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/nedap/transit-go/constants"
 )
 
 type baseEmitter struct {
-	buffer          *bytes.Buffer
-	writeHandlerMap WriteHandlerMap
-	emitter         Emitter
+	handlers *writeHandlers
+	emitter  Emitter
+	// canonicalOrder writes map entries and set elements ordered by their keys
+	canonicalOrder bool
+	// sortKeyEmitter is set in the emitter of a sort key. It writes the sort keys of
+	// nested map keys and set elements as is, so each of them is encoded once.
+	sortKeyEmitter *JsonEmitter
 }
 
+// encodedSortKey is a sort key, which the emitter of a sort key writes as is
+type encodedSortKey string
+
 func escape(str string) string {
 	length := len(str)
 	if length > 0 {
 		r := str[0]
 		if r == constants.ESC || r == constants.SUB || r == constants.RESERVED {
-			return fmt.Sprintf("%s%s", constants.ESC, str)
+			return constants.ESC_STR + str
 		}
 	}
 	return str
@@ -34,7 +42,6 @@ func (e *baseEmitter) emitTagged(t string, obj interface{}, ignored bool, cache
 	if err != nil {
 		return err
 	}
-	e.buffer.WriteString(",")
 	err = e.marshal(obj, false, cache)
 	if err != nil {
 		return err
@@ -58,22 +65,134 @@ func (e *baseEmitter) emitEncoded(t string, handler WriteHandler, obj interface{
 				return fmt.Errorf("%+v cannot be encoded as string", obj)
 			}
 		} else {
-			return e.emitTagged(t, repr, asMapKey, cache)
+			return e.emitter.emitTagged(t, repr, asMapKey, cache)
 		}
 	} else if asMapKey {
 		return fmt.Errorf("Cannot use %+v as a map key", obj)
 	} else {
-		return e.emitTagged(t, handler.Rep(obj), asMapKey, cache)
+		repr := handler.Rep(obj)
+		if e.canonicalOrder {
+			var err error
+			repr, err = e.canonicalRep(t, repr)
+			if err != nil {
+				return err
+			}
+		}
+		return e.emitter.emitTagged(t, repr, asMapKey, cache)
 	}
 }
 
 func (e *baseEmitter) emitMap(m interface{}, ignored bool, cache WriteCache) error {
-
-	entries, _ := m.(mapEntries)
+	var entries mapEntries
+	switch rep := m.(type) {
+	case mapEntries:
+		entries = rep
+	case Map:
+		entries = mapEntries(rep.entries)
+	default:
+		var err error
+		entries, err = mapToMapEntries(m)
+		if err != nil {
+			return fmt.Errorf("Cannot emit map when obj is not a map; %+v", m)
+		}
+	}
+	if e.canonicalOrder {
+		sorted, sortKeys, err := e.sortEntries(entries)
+		if err != nil {
+			return err
+		}
+		entries = sorted
+		if e.sortKeyEmitter != nil {
+			for i := range entries {
+				entries[i].key = encodedSortKey(sortKeys[i])
+			}
+		}
+	}
 
 	return e.emitter.emitActualMap(entries, ignored, cache)
 }
 
+// canonicalRep orders the elements of a set, and the keys and values of a cmap
+func (e *baseEmitter) canonicalRep(t string, repr interface{}) (interface{}, error) {
+	items, ok := repr.([]interface{})
+	if !ok {
+		return repr, nil
+	}
+
+	var entries mapEntries
+	switch t {
+	case "set":
+		for _, item := range items {
+			entries = append(entries, mapEntry{key: item})
+		}
+	case "cmap":
+		for i := 0; i+1 < len(items); i += 2 {
+			entries = append(entries, mapEntry{key: items[i], value: items[i+1]})
+		}
+	default:
+		return repr, nil
+	}
+
+	sortedEntries, sortKeys, err := e.sortEntries(entries)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]interface{}, 0, len(items))
+	for i, entry := range sortedEntries {
+		var key interface{} = entry.key
+		if e.sortKeyEmitter != nil {
+			key = encodedSortKey(sortKeys[i])
+		}
+		if t == "set" {
+			sorted = append(sorted, key)
+		} else {
+			sorted = append(sorted, key, entry.value)
+		}
+	}
+	return sorted, nil
+}
+
+// sortEntries returns a copy of entries ordered by the sort keys of their keys,
+// and those sort keys
+func (e *baseEmitter) sortEntries(entries mapEntries) (mapEntries, []string, error) {
+	type keyedEntry struct {
+		sortKey string
+		entry   mapEntry
+	}
+	keyed := make([]keyedEntry, len(entries))
+	for i, entry := range entries {
+		sortKey, err := e.sortKey(entry.key)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyed[i] = keyedEntry{sortKey: sortKey, entry: entry}
+	}
+	sort.SliceStable(keyed, func(a, b int) bool {
+		return keyed[a].sortKey < keyed[b].sortKey
+	})
+
+	sorted := make(mapEntries, len(entries))
+	sortKeys := make([]string, len(entries))
+	for i, k := range keyed {
+		sorted[i] = k.entry
+		sortKeys[i] = k.sortKey
+	}
+	return sorted, sortKeys, nil
+}
+
+// sortKey returns the JSON encoding of obj without caching, which only depends on
+// its value
+func (e *baseEmitter) sortKey(obj interface{}) (string, error) {
+	var buffer bytes.Buffer
+	keyEmitter := &JsonEmitter{writer: &buffer}
+	keyEmitter.base = baseEmitter{handlers: e.handlers, emitter: keyEmitter, canonicalOrder: true, sortKeyEmitter: keyEmitter}
+	err := keyEmitter.base.marshal(obj, false, NewWriteCache(false))
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
 func (e *baseEmitter) emitArray(obj interface{}, ignored bool, cache WriteCache) error {
 	value := reflect.ValueOf(obj)
 	kind := value.Kind()
@@ -88,9 +207,9 @@ func (e *baseEmitter) emitArray(obj interface{}, ignored bool, cache WriteCache)
 	}
 
 	for i := 0; i < value.Len(); i++ {
-		e.marshal(value.Index(i).Interface(), false, cache)
-		if i < value.Len()-1 {
-			e.buffer.WriteString(",")
+		err = e.marshal(value.Index(i).Interface(), false, cache)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -98,60 +217,96 @@ func (e *baseEmitter) emitArray(obj interface{}, ignored bool, cache WriteCache)
 }
 
 func (e *baseEmitter) marshal(obj interface{}, asMapKey bool, cache WriteCache) error {
-	var err error
-	handler, err := e.writeHandlerMap.lookupHandler(obj)
-	supported := false
-
-	if err == nil {
-		tag := handler.Tag(obj)
-
-		if tag != "" {
-			supported = true
-			if len(tag) == 1 {
-				switch tag[0] {
-				case '_':
-					err = e.emitter.emitNil(asMapKey, cache)
-				case 's':
-					err = e.emitter.emitString("", "", escape((handler.Rep(obj)).(string)), asMapKey, cache)
-				case '?':
-					err = e.emitter.emitBoolean((handler.Rep(obj)).(bool), asMapKey, cache)
-				case 'i':
-					err = e.emitter.emitInteger((handler.Rep(obj)).(int), asMapKey, cache)
-				case 'd':
-					err = e.emitter.emitDouble((handler.Rep(obj)).(float64), asMapKey, cache)
-				case 'b':
-					err = e.emitter.emitBinary((handler.Rep(obj)).([]byte), asMapKey, cache)
-				case '\'':
-					err = e.emitTagged(tag, handler.Rep(obj), false, cache)
-				default:
-					err = e.emitEncoded(tag, handler, obj, asMapKey, cache)
-				}
-			} else {
-				switch tag {
-				case "array":
-					err = e.emitArray(handler.Rep(obj), asMapKey, cache)
-				case "map":
-					err = e.emitMap(handler.Rep(obj), asMapKey, cache)
-				default:
-					err = e.emitEncoded(tag, handler, obj, asMapKey, cache)
-				}
-			}
-			if err != nil {
-				return err
-			}
-			return e.emitter.flushWriter()
-		}
+	if key, ok := obj.(encodedSortKey); ok && e.sortKeyEmitter != nil {
+		e.sortKeyEmitter.writeRaw(string(key))
+		return nil
+	}
+
+	handler, err := e.handlers.lookupHandler(obj)
+	if err != nil {
 		return err
 	}
-	if !supported {
+
+	tag := handler.Tag(obj)
+	if tag == "" {
 		return fmt.Errorf("%s is not supported", reflect.TypeOf(obj).String())
 	}
-	return nil
+
+	if len(tag) == 1 {
+		switch tag[0] {
+		case '_':
+			return e.emitter.emitNil(asMapKey, cache)
+		case 's':
+			if str, ok := handler.Rep(obj).(string); ok {
+				return e.emitter.emitString("", "", escape(str), asMapKey, cache)
+			}
+			return fmt.Errorf("Cannot write %+v with tag s, its representation is not a string", obj)
+		case '\'':
+			return e.emitter.emitTagged(tag, handler.Rep(obj), false, cache)
+		}
+		return e.emitScalar(tag, handler, obj, asMapKey, cache)
+	}
+
+	switch tag {
+	case "array":
+		return e.emitArray(handler.Rep(obj), asMapKey, cache)
+	case "map":
+		return e.emitMap(handler.Rep(obj), asMapKey, cache)
+	default:
+		return e.emitEncoded(tag, handler, obj, asMapKey, cache)
+	}
+}
+
+// emitScalar writes a value with a single character tag. A boolean, integer, float
+// or byte slice tag with a representation of that type is written as such, any
+// other string representation is written as an encoded string.
+func (e *baseEmitter) emitScalar(t string, handler WriteHandler, obj interface{}, asMapKey bool, cache WriteCache) error {
+	repr := handler.Rep(obj)
+	switch t[0] {
+	case '?':
+		if b, ok := repr.(bool); ok {
+			return e.emitter.emitBoolean(b, asMapKey, cache)
+		}
+	case 'i':
+		if i, ok := interfaceToInt64(repr); ok {
+			return e.emitter.emitInteger(i, asMapKey, cache)
+		}
+	case 'd':
+		switch d := repr.(type) {
+		case float64:
+			return e.emitter.emitDouble(d, asMapKey, cache)
+		case float32:
+			return e.emitter.emitDouble(float64(d), asMapKey, cache)
+		}
+	case 'b':
+		if b, ok := repr.([]byte); ok {
+			return e.emitter.emitBinary(b, asMapKey, cache)
+		}
+	default:
+		return e.emitEncoded(t, handler, obj, asMapKey, cache)
+	}
+
+	if _, ok := repr.(string); !ok {
+		return fmt.Errorf("Cannot write %+v with tag %s, its representation is a %T", obj, t, repr)
+	}
+	return e.emitEncoded(t, handler, obj, asMapKey, cache)
+}
+
+// emitTop marshals obj as a top level value into the buffer of the emitter, and
+// copies it to the underlying writer once it is complete. When obj cannot be
+// marshalled, nothing of it is written.
+func (e *baseEmitter) emitTop(obj interface{}, cache WriteCache) error {
+	err := e.marshalTop(obj, cache)
+	if err != nil {
+		e.emitter.discardWriter()
+		return err
+	}
+	return e.emitter.flushWriter()
 }
 
 func (e *baseEmitter) marshalTop(obj interface{}, cache WriteCache) error {
 	object := obj
-	handler, err := e.writeHandlerMap.lookupHandler(obj)
+	handler, err := e.handlers.lookupHandler(obj)
 	if err != nil {
 		return err
 	}
@@ -36,6 +36,12 @@ func (p *baseParser) parseString(str string) (interface{}, error) {
 	return str, nil
 }
 
+// misplacedTagError reports a tag that is neither the first item of an array of
+// two nor the only key of a map, the two shapes of a tagged value
+func misplacedTagError(tag Tag) error {
+	return fmt.Errorf("Tag %s is not the first of an array of two or the only key of a map", string(tag))
+}
+
 func (p *baseParser) decode(tag string, rep interface{}) (interface{}, error) {
 	handler, err := p.readHandlerMap.lookupHandler(tag)
 	if err == nil {
@@ -43,7 +49,7 @@ func (p *baseParser) decode(tag string, rep interface{}) (interface{}, error) {
 		if !ok {
 			return nil, fmt.Errorf("Could not decode %s (%s) because the handler is not a ReadHandler", tag, rep)
 		}
-		return readHandler.FromRep(rep.(string))
+		return readHandler.FromRep(rep)
 	} else if p.defaultHandler != nil {
 		return p.defaultHandler.FromRep(tag, rep)
 	} else {
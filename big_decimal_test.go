@@ -0,0 +1,115 @@
+package transit_go
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BigDecimal", func() {
+	var parse = func(str string) BigDecimal {
+		d, err := ParseBigDecimal(str)
+		Expect(err).To(BeNil())
+		return d
+	}
+
+	It("parses the unscaled value and the scale", func() {
+		d := parse("-12.340")
+		Expect(d.Unscaled.String()).To(Equal("-12340"))
+		Expect(d.Scale).To(Equal(int32(3)))
+
+		d = parse("1.5E+10")
+		Expect(d.Unscaled.String()).To(Equal("15"))
+		Expect(d.Scale).To(Equal(int32(-9)))
+
+		d = parse("25e-3")
+		Expect(d.Unscaled.String()).To(Equal("25"))
+		Expect(d.Scale).To(Equal(int32(3)))
+	})
+
+	It("does not parse invalid numbers", func() {
+		for _, str := range []string{"", "-", "1.2.3", "abc", "1E", "1e99999999999", "0x10"} {
+			_, err := ParseBigDecimal(str)
+			Expect(err).NotTo(BeNil(), str)
+		}
+	})
+
+	It("formats numbers like java.math.BigDecimal", func() {
+		for _, str := range []string{"0", "0.1", "-12.340", "123", "0.000001", "1E-7", "1.23E+5", "-1E+3", "12345678901234567890.123456789"} {
+			Expect(parse(str).String()).To(Equal(str))
+		}
+		Expect(BigDecimal{}.String()).To(Equal("0"))
+		Expect(NewBigDecimal(big.NewInt(5), 2).String()).To(Equal("0.05"))
+	})
+
+	It("compares values regardless of scale", func() {
+		Expect(parse("1.0").Cmp(parse("1.00"))).To(Equal(0))
+		Expect(parse("1E+2").Cmp(parse("100"))).To(Equal(0))
+		Expect(parse("0.1").Cmp(parse("0.09"))).To(Equal(1))
+		Expect(parse("0.1").Rat().String()).To(Equal("1/10"))
+	})
+
+	It("writes and reads ~f without loss of precision", func() {
+		d := parse("0.1000000000000000000000000000001")
+		data, err := Marshal([]interface{}{d})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~f0.1000000000000000000000000000001\"]"))
+
+		var result []BigDecimal
+		Expect(Unmarshal(data, &result)).To(Succeed())
+		Expect(result[0].String()).To(Equal(d.String()))
+		Expect(result[0].Cmp(d)).To(Equal(0))
+	})
+
+	It("reads ~f as a BigDecimal", func() {
+		reader := NewJSONReader(bytes.NewBufferString("[\"~#'\",\"~f12.50\"]"))
+		result, err := reader.Read()
+		Expect(err).To(BeNil())
+		Expect(result.(BigDecimal).String()).To(Equal("12.50"))
+	})
+
+	It("writes big decimals as map keys and in MessagePack", func() {
+		data, err := Marshal(map[BigDecimal]string{parse("1.5"): "x"}, WithFormat(FormatJSONVerbose))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("{\"~f1.5\":\"x\"}"))
+
+		data, err = Marshal(parse("-0.25"), WithFormat(FormatMsgpack))
+		Expect(err).To(BeNil())
+		var d BigDecimal
+		Expect(Unmarshal(data, &d, WithFormat(FormatMsgpack))).To(Succeed())
+		Expect(d.String()).To(Equal("-0.25"))
+	})
+
+	It("decodes into floats", func() {
+		var f float64
+		Expect(Unmarshal([]byte("[\"~#'\",\"~f0.25\"]"), &f)).To(Succeed())
+		Expect(f).To(Equal(0.25))
+	})
+
+	It("writes a *big.Float", func() {
+		data, err := Marshal([]interface{}{big.NewFloat(0.1), new(big.Float).SetPrec(200).SetInt64(3)})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~f0.1\",\"~f3\"]"))
+	})
+
+	It("writes an infinite *big.Float as a special number", func() {
+		data, err := Marshal([]interface{}{new(big.Float).SetInf(false), new(big.Float).SetInf(true)})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~zINF\",\"~z-INF\"]"))
+
+		var result []float64
+		Expect(Unmarshal(data, &result)).To(Succeed())
+		Expect(math.IsInf(result[0], 1)).To(BeTrue())
+		Expect(math.IsInf(result[1], -1)).To(BeTrue())
+	})
+
+	It("writes a nil *big.Float as null", func() {
+		var f *big.Float
+		data, err := Marshal([]interface{}{f})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[null]"))
+	})
+})
@@ -0,0 +1,74 @@
+package transit_go
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nedap/transit-go/constants"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// generateCachedPayload returns maps with n distinct keyword keys and values,
+// which all share the keyword key :shared, so its cache code changes every time
+// the cache starts over
+func generateCachedPayload(n int) []interface{} {
+	var payload []interface{}
+	for i := 0; i < n; i++ {
+		payload = append(payload, NewMap(
+			Keyword(fmt.Sprintf("entity/key-%d", i)), i,
+			Keyword("shared"), Keyword(fmt.Sprintf("value/v-%d", i)),
+		))
+	}
+	return payload
+}
+
+var _ = Describe("Caches", func() {
+	var keyword = func(i int) string {
+		return fmt.Sprintf("~:k%04d", i)
+	}
+
+	It("start over after MaxCacheEntries entries when writing", func() {
+		cache := NewWriteCache(true)
+		for i := 0; i < constants.MaxCacheEntries; i++ {
+			Expect(cache.CacheWrite(keyword(i), false)).To(Equal(keyword(i)))
+		}
+		Expect(cache.CacheWrite(keyword(0), false)).To(Equal("^0"))
+		Expect(cache.CacheWrite(keyword(constants.MaxCacheEntries-1), false)).To(Equal("^[["))
+
+		Expect(cache.CacheWrite("~:next", false)).To(Equal("~:next"))
+		Expect(cache.CacheWrite("~:next", false)).To(Equal("^0"))
+		Expect(cache.CacheWrite(keyword(0), false)).To(Equal(keyword(0)))
+		Expect(cache.CacheWrite(keyword(0), false)).To(Equal("^1"))
+	})
+
+	It("start over after MaxCacheEntries entries when reading", func() {
+		cache := NewReadCache()
+		for i := 0; i < constants.MaxCacheEntries; i++ {
+			Expect(cache.CacheRead(keyword(i), false, nil)).To(Equal(keyword(i)))
+		}
+		Expect(cache.CacheRead("^[[", false, nil)).To(Equal(keyword(constants.MaxCacheEntries - 1)))
+
+		Expect(cache.CacheRead("~:next", false, nil)).To(Equal("~:next"))
+		Expect(cache.CacheRead("^0", false, nil)).To(Equal("~:next"))
+		_, err := cache.CacheRead("^1", false, nil)
+		Expect(err).To(MatchError("Unknown cache code ^1"))
+	})
+
+	It("roundtrip payloads with thousands of cached keywords", func() {
+		payload := generateCachedPayload(5000)
+
+		for _, format := range []Format{FormatJSON, FormatMsgpack} {
+			data, err := Marshal(payload, WithFormat(format))
+			Expect(err).To(BeNil())
+			if format == FormatJSON {
+				// written in full again after every time the cache started over
+				Expect(strings.Count(string(data), "\"~:shared\"")).To(BeNumerically(">=", 5))
+			}
+
+			var result interface{}
+			Expect(Unmarshal(data, &result, WithFormat(format))).To(Succeed())
+			Expect(valueEquals(result, payload)).To(BeTrue())
+		}
+	})
+})
@@ -0,0 +1,52 @@
+package transit_go
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Decoder reads a sequence of transit values from an input stream.
+type Decoder struct {
+	parser Parser
+}
+
+// NewDecoder returns a Decoder that reads from r, by default transit JSON (or JSON-Verbose).
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	o := newOptions(opts)
+	handlers := o.decodeHandlers()
+
+	if o.format == FormatMsgpack {
+		return &Decoder{parser: newMsgpackReaderParser(r, handlers)}
+	}
+	return &Decoder{parser: newJsonReaderParser(r, handlers)}
+}
+
+// NewMsgpackDecoder returns a Decoder that reads transit MessagePack from r.
+func NewMsgpackDecoder(r io.Reader, opts ...Option) *Decoder {
+	return NewDecoder(r, append(opts, WithFormat(FormatMsgpack))...)
+}
+
+// Decode reads the next value from the stream and stores it in the value pointed
+// to by v. Into a *interface{} the value is stored as it is read, other types are
+// filled using reflection: arrays, lists and sets decode into slices and arrays,
+// maps into maps and structs, and scalars into the Go types they convert to
+// without loss. A value that does not fit results in an *UnmarshalTypeError.
+// At the end of the stream io.EOF is returned.
+func (d *Decoder) Decode(v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("Cannot decode into %T, expected a non-nil pointer", v)
+	}
+
+	val, err := d.parser.parse(NewReadCache())
+	if err != nil {
+		return err
+	}
+	return assignValue(target.Elem(), val, "")
+}
+
+// More reports whether there is another value in the stream.
+func (d *Decoder) More() bool {
+	return d.parser.more()
+}
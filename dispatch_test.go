@@ -0,0 +1,187 @@
+package transit_go
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type dispatchLevel int8
+type dispatchName string
+type dispatchNames []dispatchName
+type dispatchRaw []byte
+type dispatchByte uint8
+
+type dispatchColor struct {
+	R, G, B uint8
+}
+
+func (c dispatchColor) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+type dispatchShape interface {
+	Area() int
+}
+
+type dispatchSquare struct {
+	Side int
+}
+
+func (s dispatchSquare) Area() int {
+	return s.Side * s.Side
+}
+
+func (s dispatchSquare) String() string {
+	return fmt.Sprintf("square %d", s.Side)
+}
+
+var _ = Describe("Write handler dispatch", func() {
+	var buffer bytes.Buffer
+
+	AfterEach(func() {
+		buffer.Reset()
+	})
+
+	Context("by kind", func() {
+		writer := NewJSONWriter(&buffer)
+
+		It("marshals all sizes of integers", func() {
+			result := write(writer, []interface{}{int8(-8), int16(16), uint16(16), uint32(32), uint(7), uint8(8)})
+			Expect(result).To(Equal("[-8,16,16,32,7,8]"))
+		})
+
+		It("marshals slices of any type", func() {
+			result := write(writer, []interface{}{[]uint{1, 2}, [][]int{{1}, {2, 3}}, []float32{}})
+			Expect(result).To(Equal("[[1,2],[[1],[2,3]],[]]"))
+		})
+
+		It("marshals named types as their underlying type", func() {
+			names := dispatchNames{"a", "~b"}
+			result := write(writer, []interface{}{dispatchLevel(3), names, dispatchRaw("hi")})
+			Expect(result).To(Equal("[3,[\"a\",\"~~b\"],\"~baGk=\"]"))
+		})
+
+		It("marshals slices of a named byte type as arrays", func() {
+			result := write(writer, []dispatchByte{1, 2})
+			Expect(result).To(Equal("[1,2]"))
+		})
+
+		It("marshals named types as map keys", func() {
+			result := write(writer, map[dispatchLevel]bool{3: true})
+			Expect(result).To(Equal("[\"^ \",\"~i3\",true]"))
+		})
+
+		It("marshals structs by kind when they do not implement a registered interface", func() {
+			result := write(writer, dispatchSquare{Side: 2})
+			Expect(result).To(Equal("[\"^ \",\"Side\",2]"))
+		})
+
+		It("returns an error for unsupported kinds", func() {
+			err := writer.Write(make(chan int))
+			Expect(err).To(MatchError("No handler found for type chan int"))
+		})
+	})
+
+	Context("by interface", func() {
+		stringerHandler := WriteHandler{
+			Name: "Stringer Write Handler",
+			Tag:  func(obj interface{}) string { return "s" },
+			Rep: func(obj interface{}) interface{} {
+				return obj.(fmt.Stringer).String()
+			},
+		}
+		shapeHandler := WriteHandler{
+			Name: "Shape Write Handler",
+			Tag:  func(obj interface{}) string { return "shape" },
+			Rep: func(obj interface{}) interface{} {
+				return obj.(dispatchShape).Area()
+			},
+		}
+
+		It("writes a Map as a map, although it implements fmt.Stringer", func() {
+			writer := NewJSONWriterWithHandlers(&buffer, WriteHandlerMap{
+				reflect.TypeOf((*fmt.Stringer)(nil)).Elem(): stringerHandler,
+			})
+			result := write(writer, NewMap("a", 1))
+			Expect(result).To(Equal("[\"^ \",\"a\",1]"))
+		})
+
+		It("uses the handler of an interface the type implements", func() {
+			writer := NewJSONWriterWithHandlers(&buffer, WriteHandlerMap{
+				reflect.TypeOf((*fmt.Stringer)(nil)).Elem(): stringerHandler,
+			})
+			result := write(writer, []interface{}{dispatchColor{R: 255}, "plain"})
+			Expect(result).To(Equal("[\"#ff0000\",\"plain\"]"))
+		})
+
+		It("prefers a handler registered for the type itself", func() {
+			writer := NewJSONWriterWithHandlers(&buffer, WriteHandlerMap{
+				reflect.TypeOf((*dispatchShape)(nil)).Elem(): shapeHandler,
+				reflect.TypeOf(dispatchSquare{}):             stringerHandler,
+			})
+			result := write(writer, dispatchSquare{Side: 3})
+			Expect(result).To(Equal("[\"~#'\",\"square 3\"]"))
+		})
+
+		It("returns an error when the type implements more than one registered interface", func() {
+			writer := NewJSONWriterWithHandlers(&buffer, WriteHandlerMap{
+				reflect.TypeOf((*fmt.Stringer)(nil)).Elem():  stringerHandler,
+				reflect.TypeOf((*dispatchShape)(nil)).Elem(): shapeHandler,
+			})
+			err := writer.Write(dispatchSquare{Side: 3})
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(HavePrefix("More than one handler found for type transit_go.dispatchSquare"))
+		})
+	})
+
+	It("caches the handler it resolves for a type without changing the handler map", func() {
+		handlers := defaultWriteHandlers()
+		resolver := newWriteHandlers(handlers)
+
+		handler, err := resolver.lookupHandler(int8(1))
+		Expect(err).To(BeNil())
+		Expect(handler.Tag(int8(1))).To(Equal("i"))
+
+		_, found := resolver.resolved.Load(reflect.TypeOf(int8(0)))
+		Expect(found).To(BeTrue())
+		_, found = handlers[reflect.TypeOf(int8(0))]
+		Expect(found).To(BeFalse())
+	})
+
+	It("keeps the handlers it resolves between writes without custom handlers", func() {
+		type cachedKey int16
+		_, found := defaultHandlers.resolved.Load(reflect.TypeOf(cachedKey(0)))
+		Expect(found).To(BeFalse())
+
+		_, err := Marshal(NewMap(cachedKey(1), "one"))
+		Expect(err).To(BeNil())
+		_, found = defaultHandlers.resolved.Load(reflect.TypeOf(cachedKey(0)))
+		Expect(found).To(BeTrue())
+	})
+
+	It("resolves handlers for concurrent writes", func() {
+		resolver := newWriteHandlers(defaultWriteHandlers())
+		values := []interface{}{int8(1), uint16(2), dispatchColor{}, []uint{1}, map[Keyword]int8{}}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					for _, value := range values {
+						_, err := resolver.lookupHandler(value)
+						Expect(err).To(BeNil())
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+})
@@ -5,14 +5,15 @@ type mapEntry struct {
 	value interface{}
 }
 
-type mapEntries Set
+// mapEntries are the entries of a map in the order they should be written
+type mapEntries []mapEntry
 
 type Emitter interface {
 	emit(obj interface{}, asMapKey bool, cache WriteCache) error
 	emitNil(asMapKey bool, cache WriteCache) error
 	emitString(prefix string, tag string, str string, asMapKey bool, cache WriteCache) error
 	emitBoolean(b bool, asMapKey bool, cache WriteCache) error
-	emitInteger(i int, asMapKey bool, cache WriteCache) error
+	emitInteger(i int64, asMapKey bool, cache WriteCache) error
 	emitDouble(f float64, asMapKey bool, cache WriteCache) error
 	emitBinary(bytes []byte, asMapKey bool, cache WriteCache) error
 	emitArrayStart(size int) error
@@ -20,6 +21,9 @@ type Emitter interface {
 	emitMapStart(size int) error
 	emitMapEnd() error
 	emitActualMap(entries mapEntries, ignored bool, cache WriteCache) error
+	emitTagged(t string, obj interface{}, ignored bool, cache WriteCache) error
 	prefersStrings() bool
 	flushWriter() error
+	discardWriter()
+	setCanonicalOrder(enabled bool)
 }
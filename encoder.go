@@ -0,0 +1,44 @@
+package transit_go
+
+import "io"
+
+// Encoder writes transit values to an output stream. Every value is encoded into
+// a buffer that is written out once the value is complete, so consecutive values
+// can be streamed to files, network connections or compressing writers, and a
+// value that cannot be encoded leaves no partial output behind.
+type Encoder struct {
+	emitter      Emitter
+	cacheEnabled bool
+}
+
+// NewEncoder returns an Encoder that writes to w, by default as transit JSON.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	o := newOptions(opts)
+
+	var encoder *Encoder
+	switch o.format {
+	case FormatJSONVerbose:
+		encoder = &Encoder{emitter: newJsonVerboseEmitter(w, writerHandlers(o.writeHandlers, true)), cacheEnabled: false}
+	case FormatMsgpack:
+		encoder = &Encoder{emitter: newMsgpackEmitter(w, writerHandlers(o.writeHandlers, false)), cacheEnabled: o.caching}
+	default:
+		encoder = &Encoder{emitter: newJsonEmitter(w, writerHandlers(o.writeHandlers, false)), cacheEnabled: o.caching}
+	}
+	encoder.emitter.setCanonicalOrder(o.canonicalOrder)
+	return encoder
+}
+
+// NewJSONVerboseEncoder returns an Encoder that writes transit JSON-Verbose to w.
+func NewJSONVerboseEncoder(w io.Writer, opts ...Option) *Encoder {
+	return NewEncoder(w, append(opts, WithFormat(FormatJSONVerbose))...)
+}
+
+// NewMsgpackEncoder returns an Encoder that writes transit MessagePack to w.
+func NewMsgpackEncoder(w io.Writer, opts ...Option) *Encoder {
+	return NewEncoder(w, append(opts, WithFormat(FormatMsgpack))...)
+}
+
+// Encode writes obj to the stream once it is encoded completely.
+func (e *Encoder) Encode(obj interface{}) error {
+	return e.emitter.emit(obj, false, NewWriteCache(e.cacheEnabled))
+}
@@ -0,0 +1,198 @@
+package transit_go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// valueEquals reports whether two values are equal the way transit values are
+// compared: composite values by their contents, integers of any type by their
+// value, and big numbers and times by the value they represent. Floats are equal
+// when == says so, and NaN is equal to NaN as well, so a NaN key can be found.
+func valueEquals(a, b interface{}) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case Map:
+		y, ok := b.(Map)
+		return ok && x.Equal(y)
+	case Set:
+		y, ok := b.(Set)
+		return ok && x.Equal(y)
+	case TaggedValue:
+		y, ok := b.(TaggedValue)
+		return ok && x.Tag == y.Tag && valueEquals(x.Rep, y.Rep)
+	case *big.Int:
+		y, ok := b.(*big.Int)
+		return ok && x.Cmp(y) == 0
+	case *big.Rat:
+		y, ok := b.(*big.Rat)
+		return ok && x.Cmp(y) == 0
+	case BigDecimal:
+		// like java.math.BigDecimal, 1.0 and 1.00 are different values
+		y, ok := b.(BigDecimal)
+		return ok && x.Scale == y.Scale && x.unscaled().Cmp(y.unscaled()) == 0
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	case []byte:
+		// compared to other slices element by element below, like b compares to x
+		if y, ok := b.([]byte); ok {
+			return bytes.Equal(x, y)
+		}
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !vb.IsValid() {
+		return false
+	}
+	if isIntegerKind(va.Kind()) && isIntegerKind(vb.Kind()) {
+		return integerEquals(va, vb)
+	}
+	if isArrayKind(va.Kind()) && isArrayKind(vb.Kind()) {
+		// arrays are equal when their elements are, like []int and []interface{}
+		if va.Len() != vb.Len() {
+			return false
+		}
+		for i := 0; i < va.Len(); i++ {
+			if !valueEquals(va.Index(i).Interface(), vb.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	if va.Type() != vb.Type() {
+		return false
+	}
+
+	switch va.Kind() {
+	case reflect.Float32, reflect.Float64:
+		x, y := va.Float(), vb.Float()
+		return x == y || (math.IsNaN(x) && math.IsNaN(y))
+	case reflect.Map:
+		if va.Len() != vb.Len() {
+			return false
+		}
+		for _, key := range va.MapKeys() {
+			other := vb.MapIndex(key)
+			if !other.IsValid() || !valueEquals(va.MapIndex(key).Interface(), other.Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// valueHash returns a hash of a value, which is the same for values that are
+// equal according to valueEquals
+func valueHash(v interface{}) uint64 {
+	h := fnv.New64a()
+
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case Map:
+		var hash uint64
+		x.Range(func(key, value interface{}) bool {
+			hash += valueHash(key)*31 + valueHash(value)
+			return true
+		})
+		return hash ^ 0x6d6170
+	case Set:
+		var hash uint64
+		for _, item := range x.Items() {
+			hash += valueHash(item)
+		}
+		return hash ^ 0x736574
+	case TaggedValue:
+		h.Write([]byte(x.Tag))
+		return h.Sum64()*31 + valueHash(x.Rep)
+	case *big.Int:
+		h.Write([]byte("n" + x.String()))
+		return h.Sum64()
+	case *big.Rat:
+		h.Write([]byte("r" + x.String()))
+		return h.Sum64()
+	case BigDecimal:
+		h.Write([]byte("f" + x.String()))
+		return h.Sum64()
+	case time.Time:
+		binary.Write(h, binary.BigEndian, x.UnixNano())
+		return h.Sum64()
+	}
+
+	rv := reflect.ValueOf(v)
+	if isArrayKind(rv.Kind()) {
+		hash := uint64(0x6172726179)
+		for i := 0; i < rv.Len(); i++ {
+			hash = hash*31 + valueHash(rv.Index(i).Interface())
+		}
+		return hash
+	}
+	h.Write([]byte(rv.Type().String()))
+
+	switch kind := rv.Kind(); {
+	case isIntegerKind(kind):
+		// integers of different types with the same value are equal
+		h.Reset()
+		if kind >= reflect.Uint && kind <= reflect.Uintptr && rv.Uint() > math.MaxInt64 {
+			binary.Write(h, binary.BigEndian, rv.Uint())
+			h.Write([]byte("u"))
+		} else if kind >= reflect.Uint && kind <= reflect.Uintptr {
+			binary.Write(h, binary.BigEndian, int64(rv.Uint()))
+		} else {
+			binary.Write(h, binary.BigEndian, rv.Int())
+		}
+	case kind == reflect.String:
+		h.Write([]byte(rv.String()))
+	case kind == reflect.Bool:
+		binary.Write(h, binary.BigEndian, rv.Bool())
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		f := rv.Float()
+		if f == 0 {
+			// -0.0 is equal to 0.0
+			f = 0
+		} else if math.IsNaN(f) {
+			f = math.NaN()
+		}
+		binary.Write(h, binary.BigEndian, f)
+	case kind == reflect.Map:
+		hash := h.Sum64()
+		for _, key := range rv.MapKeys() {
+			hash += valueHash(key.Interface())*31 + valueHash(rv.MapIndex(key).Interface())
+		}
+		return hash
+	}
+	// other values only hash their type, and are told apart by valueEquals
+	return h.Sum64()
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Uintptr
+}
+
+func isArrayKind(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+func integerEquals(a, b reflect.Value) bool {
+	aSigned := a.Kind() <= reflect.Int64
+	bSigned := b.Kind() <= reflect.Int64
+
+	switch {
+	case aSigned && bSigned:
+		return a.Int() == b.Int()
+	case !aSigned && !bSigned:
+		return a.Uint() == b.Uint()
+	case aSigned:
+		return a.Int() >= 0 && uint64(a.Int()) == b.Uint()
+	default:
+		return b.Int() >= 0 && uint64(b.Int()) == a.Uint()
+	}
+}
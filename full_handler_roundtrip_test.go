@@ -79,14 +79,15 @@ var _ = Describe("Custom handlers", func() {
 		pointReader := ReadHandler{
 			Name: "Point Read Handler",
 			FromRep: func(rep interface{}) (interface{}, error) {
-				repAsMap, ok := rep.(map[*MapKey]interface{})
+				repAsMap, ok := rep.(Map)
 				if !ok {
-					return nil, fmt.Errorf("Expected to be able to type assert to map[*MapKey]interface{}")
+					return nil, fmt.Errorf("Expected to be able to type assert to Map")
 				}
 				pointMap := make(map[string]interface{})
-				for mapKey, v := range repAsMap {
-					pointMap[mapKey.Key.(string)] = v
-				}
+				repAsMap.Range(func(key, v interface{}) bool {
+					pointMap[key.(string)] = v
+					return true
+				})
 
 				res := Point{x: pointMap["x"].(float64), y: pointMap["y"].(float64)}
 
@@ -97,14 +98,15 @@ var _ = Describe("Custom handlers", func() {
 		graphReader := ReadHandler{
 			Name: "Graph Read Handler",
 			FromRep: func(rep interface{}) (interface{}, error) {
-				repAsMap, ok := rep.(map[*MapKey]interface{})
+				repAsMap, ok := rep.(Map)
 				if !ok {
-					return nil, fmt.Errorf("Expected to be able to type assert to map[*MapKey]interface{}")
+					return nil, fmt.Errorf("Expected to be able to type assert to Map")
 				}
 				graphMap := make(map[string]interface{})
-				for mapKey, v := range repAsMap {
-					graphMap[mapKey.Key.(string)] = v
-				}
+				repAsMap.Range(func(key, v interface{}) bool {
+					graphMap[key.(string)] = v
+					return true
+				})
 
 				res := Graph{
 					LeftPoint:   graphMap["left_point"].(Point),
@@ -126,7 +128,8 @@ var _ = Describe("Custom handlers", func() {
 		readBuffer := bytes.NewBufferString(result)
 
 		reader := NewJSONReaderWithHandlers(readBuffer, customReaders)
-		readResult := reader.Read()
+		readResult, err := reader.Read()
+		Expect(err).To(BeNil())
 
 		resultAsGraph, ok := readResult.(Graph)
 		Expect(ok)
@@ -5,30 +5,69 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
 
 	"github.com/nedap/transit-go/constants"
 )
 
+// integers outside of this range cannot be represented exactly by a JavaScript
+// number, so they are written as "~i" strings
 const (
-	jsonMaxInt = 2 ^ 53 - 1
+	jsonMaxInt = 1<<53 - 1
 	jsonMinInt = -jsonMaxInt
 )
 
+// jsonScope keeps track of the array or object that is currently being written,
+// so separators can be written in between its values.
+type jsonScope struct {
+	object bool
+	count  int
+}
+
 type JsonEmitter struct {
-	buffer *bytes.Buffer
+	out    io.Writer
+	writer *bytes.Buffer
 	base   baseEmitter
+	scopes []jsonScope
+}
+
+func NewJsonEmitter(w io.Writer, writeHandlerMap WriteHandlerMap) Emitter {
+	return newJsonEmitter(w, newWriteHandlers(writeHandlerMap))
 }
 
-func NewJsonEmitter(buffer *bytes.Buffer, writeHandlerMap WriteHandlerMap) Emitter {
-	jsonEmitter := &JsonEmitter{buffer: buffer}
-	baseEmitter := baseEmitter{buffer: buffer, writeHandlerMap: writeHandlerMap, emitter: jsonEmitter}
+func newJsonEmitter(w io.Writer, handlers *writeHandlers) Emitter {
+	jsonEmitter := &JsonEmitter{out: w, writer: new(bytes.Buffer)}
+	baseEmitter := baseEmitter{handlers: handlers, emitter: jsonEmitter}
 	jsonEmitter.base = baseEmitter
 	return jsonEmitter
 }
 
+// writeSeparator writes the ',' (or ':' in between the key and value of an object)
+// that has to precede the next value in the current array or object.
+func (j *JsonEmitter) writeSeparator() {
+	if len(j.scopes) == 0 {
+		return
+	}
+	scope := &j.scopes[len(j.scopes)-1]
+	if scope.count > 0 {
+		if scope.object && scope.count%2 == 1 {
+			j.writer.WriteString(":")
+		} else {
+			j.writer.WriteString(",")
+		}
+	}
+	scope.count++
+}
+
+func (j *JsonEmitter) writeRaw(str string) {
+	j.writeSeparator()
+	j.writer.WriteString(str)
+}
+
 func (j *JsonEmitter) emit(obj interface{}, asMapKey bool, cache WriteCache) error {
-	return j.base.marshalTop(obj, cache)
+	j.scopes = j.scopes[:0]
+	return j.base.emitTop(obj, cache)
 }
 
 func (j *JsonEmitter) emitNil(asMapKey bool, cache WriteCache) error {
@@ -38,7 +77,7 @@ func (j *JsonEmitter) emitNil(asMapKey bool, cache WriteCache) error {
 			return err
 		}
 	} else {
-		j.buffer.WriteString("null")
+		j.writeRaw("null")
 		return nil
 	}
 	return nil
@@ -57,7 +96,7 @@ func (j *JsonEmitter) emitString(prefix, tag, str string, asMapKey bool, cache W
 	if err != nil {
 		return err
 	}
-	j.buffer.Write(value)
+	j.writeRaw(string(value))
 	return nil
 }
 
@@ -71,17 +110,17 @@ func (j *JsonEmitter) emitBoolean(b bool, asMapKey bool, cache WriteCache) error
 		}
 		return j.emitString(constants.ESC_STR, "?", str, asMapKey, cache)
 	} else {
-		j.buffer.WriteString(strconv.FormatBool(b))
+		j.writeRaw(strconv.FormatBool(b))
 		return nil
 	}
 }
 
-func (j *JsonEmitter) emitInteger(intValue int, asMapKey bool, cache WriteCache) error {
-	intStr := strconv.FormatInt(int64(intValue), 10)
+func (j *JsonEmitter) emitInteger(intValue int64, asMapKey bool, cache WriteCache) error {
+	intStr := strconv.FormatInt(intValue, 10)
 	if asMapKey || intValue > jsonMaxInt || intValue < jsonMinInt {
 		return j.emitString(constants.ESC_STR, "i", intStr, asMapKey, cache)
 	} else {
-		j.buffer.WriteString(intStr)
+		j.writeRaw(intStr)
 		return nil
 	}
 }
@@ -91,7 +130,7 @@ func (j *JsonEmitter) emitDouble(floatValue float64, asMapKey bool, cache WriteC
 	if asMapKey {
 		return j.emitString(constants.ESC_STR, "d", floatStr, asMapKey, cache)
 	} else {
-		j.buffer.WriteString(floatStr)
+		j.writeRaw(floatStr)
 		return nil
 	}
 }
@@ -102,63 +141,70 @@ func (j *JsonEmitter) emitBinary(bytes []byte, asMapKey bool, cache WriteCache)
 }
 
 func (j *JsonEmitter) emitArrayStart(size int) error {
-	j.buffer.WriteString("[")
+	j.writeRaw("[")
+	j.scopes = append(j.scopes, jsonScope{})
 	return nil
 }
 
 func (j *JsonEmitter) emitArrayEnd() error {
-	j.buffer.WriteString("]")
+	j.scopes = j.scopes[:len(j.scopes)-1]
+	j.writer.WriteString("]")
 	return nil
 }
 
 func (j *JsonEmitter) emitMapStart(size int) error {
-	j.buffer.WriteString("{")
+	j.writeRaw("{")
+	j.scopes = append(j.scopes, jsonScope{object: true})
 	return nil
 }
 
 func (j *JsonEmitter) emitMapEnd() error {
-	j.buffer.WriteString("}")
+	j.scopes = j.scopes[:len(j.scopes)-1]
+	j.writer.WriteString("}")
 	return nil
 }
 
+func (j *JsonEmitter) emitTagged(t string, obj interface{}, ignored bool, cache WriteCache) error {
+	return j.base.emitTagged(t, obj, ignored, cache)
+}
+
 func (j *JsonEmitter) prefersStrings() bool {
 	return true
 }
 
 func (j *JsonEmitter) flushWriter() error {
-	return nil
+	_, err := j.writer.WriteTo(j.out)
+	j.writer.Reset()
+	return err
+}
+
+func (j *JsonEmitter) discardWriter() {
+	j.writer.Reset()
+}
+
+func (j *JsonEmitter) setCanonicalOrder(enabled bool) {
+	j.base.canonicalOrder = enabled
 }
 
 func (j *JsonEmitter) emitActualMap(entries mapEntries, ignored bool, cache WriteCache) (err error) {
-	size := entries.Len()
+	size := len(entries)
 	err = j.emitArrayStart(size)
 	if err != nil {
 		return err
 	}
 	err = j.emitString("", "", constants.MAP_AS_ARRAY, false, cache)
-	if size > 0 {
-		j.buffer.WriteString(",")
-	}
 	if err != nil {
 		return err
 	}
 
-	for index, entry := range entries.Items() {
-		entry, ok := entry.(mapEntry)
-		if ok {
-			err = j.base.marshal(entry.key, true, cache)
-			if err != nil {
-				return err
-			}
-			j.buffer.WriteString(",")
-			err = j.base.marshal(entry.value, false, cache)
-			if err != nil {
-				return err
-			}
-
-			if index < size-1 {
-				j.buffer.WriteString(",")
-			}
+	for _, entry := range entries {
+		err = j.base.marshal(entry.key, true, cache)
+		if err != nil {
+			return err
+		}
+		err = j.base.marshal(entry.value, false, cache)
+		if err != nil {
+			return err
 		}
 	}
 	err = j.emitArrayEnd()
@@ -3,6 +3,8 @@ package transit_go
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
 	"strings"
 
 	"github.com/nedap/transit-go/constants"
@@ -11,11 +13,15 @@ import (
 type JsonParser struct {
 	decoder  *json.Decoder
 	base     baseParser
-	curToken *json.Token
+	curToken json.Token
+	// the next top-level token, when it was read ahead by more or atEnd
+	peeked    json.Token
+	peekedErr error
+	hasPeeked bool
 }
 
 func NewJsonParser(decoder *json.Decoder, handlers ReadHandlerMap, defaultHandler *DefaultReadHandler, mapBuilder MapReader, listBuilder ArrayReader) Parser {
-	jsonParser := JsonParser{decoder: decoder}
+	jsonParser := &JsonParser{decoder: decoder}
 
 	baseParser := baseParser{
 		readHandlerMap: handlers,
@@ -28,39 +34,77 @@ func NewJsonParser(decoder *json.Decoder, handlers ReadHandlerMap, defaultHandle
 	return jsonParser
 }
 
-func (p JsonParser) currentToken() json.Token {
-	return *p.curToken
+func (p *JsonParser) currentToken() json.Token {
+	return p.curToken
 }
 
-func (p *JsonParser) nextToken() json.Token {
-	if token, err := p.decoder.Token(); err == nil {
-		p.curToken = &token
-	} else {
-		p.curToken = nil
+func (p *JsonParser) nextToken() (json.Token, error) {
+	token, err := p.decoder.Token()
+	if err == io.EOF {
+		// the input ended in the middle of a value
+		err = io.ErrUnexpectedEOF
+	}
+	p.curToken = token
+	return token, err
+}
+
+// expectEnd advances to the end of the array or map holding a tagged value, which
+// has nothing after the representation
+func (p *JsonParser) expectEnd(tag Tag, delim string) error {
+	token, err := p.nextToken()
+	if err != nil {
+		return err
+	}
+	if !tokenEquals(token, delim) {
+		return misplacedTagError(tag)
 	}
-	return *p.curToken
+	return nil
 }
 
-func (p JsonParser) parseString(str string) (interface{}, error) {
+func (p *JsonParser) parseString(str string) (interface{}, error) {
 	return p.base.parseString(str)
 }
 
-func (p JsonParser) parseLong() (interface{}, error) {
+func (p *JsonParser) parseLong() (interface{}, error) {
 	var long int64
 	err := p.decoder.Decode(&long)
 	return long, err
 }
 
-func (p JsonParser) parse(cache ReadCache) (interface{}, error) {
-	if !p.decoder.More() {
-		return nil, fmt.Errorf("Unexpected EOF")
-	} else {
-		p.nextToken()
-		return p.parseVal(false, cache)
+// peekToken reads the next top-level token ahead, the following call to parse
+// starts at that token
+func (p *JsonParser) peekToken() (json.Token, error) {
+	if !p.hasPeeked {
+		p.peeked, p.peekedErr = p.decoder.Token()
+		p.hasPeeked = true
+	}
+	return p.peeked, p.peekedErr
+}
+
+func (p *JsonParser) more() bool {
+	// json.Decoder.More reports false for a ']' or '}' as well, which is not the
+	// end of the input but invalid data that parse reports
+	_, err := p.peekToken()
+	return err != io.EOF
+}
+
+func (p *JsonParser) atEnd() bool {
+	_, err := p.peekToken()
+	return err == io.EOF
+}
+
+func (p *JsonParser) parse(cache ReadCache) (interface{}, error) {
+	token, err := p.peekToken()
+	p.hasPeeked = false
+	if err != nil {
+		// io.EOF here is the clean end of the input, between values
+		return nil, err
 	}
+	p.curToken = token
+	return p.parseVal(false, cache)
 }
 
-func (p JsonParser) parseVal(asMapKey bool, cache ReadCache) (interface{}, error) {
+func (p *JsonParser) parseVal(asMapKey bool, cache ReadCache) (interface{}, error) {
 	token := p.currentToken()
 	if token == nil {
 		return nil, nil
@@ -73,30 +117,32 @@ func (p JsonParser) parseVal(asMapKey bool, cache ReadCache) (interface{}, error
 			return p.parseArray(asMapKey, cache, nil)
 		}
 	} else if str, ok := token.(string); ok {
-		return cache.CacheRead(str, asMapKey, p), nil
+		return cache.CacheRead(str, asMapKey, p)
 	} else if b, ok := token.(bool); ok {
 		return b, nil
 	} else if num, ok := token.(json.Number); ok {
-		if strings.Contains(num.String(), ".") {
+		if strings.ContainsAny(num.String(), ".eE") {
 			return num.Float64()
 		} else {
-			bigInt, err := num.Int64()
+			i, err := num.Int64()
 			if err != nil {
-				return nil, nil
+				// an integer that does not fit in an int64 is read as big integer
+				if bigInt, ok := new(big.Int).SetString(num.String(), 10); ok {
+					return bigInt, nil
+				}
+				return nil, err
 			}
-			return int(bigInt), nil
+			return int(i), nil
 		}
-	} else if token == nil {
-		return nil, nil
 	}
-	return nil, nil
+	return nil, fmt.Errorf("Unexpected token %v", token)
 }
 
-func (p JsonParser) parseMap(asMapKey bool, cache ReadCache, handler *MapReadHandler) (interface{}, error) {
+func (p *JsonParser) parseMap(asMapKey bool, cache ReadCache, handler *MapReadHandler) (interface{}, error) {
 	return p.parseMapUntilToken(asMapKey, cache, handler, '}')
 }
 
-func (p JsonParser) parseMapUntilToken(asMapKey bool, cache ReadCache, handler *MapReadHandler, endRune rune) (interface{}, error) {
+func (p *JsonParser) parseMapUntilToken(asMapKey bool, cache ReadCache, handler *MapReadHandler, endRune rune) (interface{}, error) {
 	var mr MapReader
 	if handler == nil {
 		mr = p.base.mapBuilder
@@ -106,7 +152,14 @@ func (p JsonParser) parseMapUntilToken(asMapKey bool, cache ReadCache, handler *
 
 	mb := mr.Init()
 
-	for !tokenEquals(p.nextToken(), string(endRune)) {
+	for first := true; ; first = false {
+		token, err := p.nextToken()
+		if err != nil {
+			return nil, err
+		}
+		if tokenEquals(token, string(endRune)) {
+			break
+		}
 
 		key, err := p.parseVal(true, cache)
 		if err != nil {
@@ -114,53 +167,25 @@ func (p JsonParser) parseMapUntilToken(asMapKey bool, cache ReadCache, handler *
 		}
 
 		if tag, ok := key.(Tag); ok {
-			valHandler, err := p.base.readHandlerMap.lookupHandler(string(tag))
-
-			var val interface{}
-			// advance to read value
-			token := p.nextToken()
-			if token != nil {
-				mapHandler, isMapHandler := valHandler.(MapReadHandler)
-				arrayHandler, isArrayHandler := valHandler.(ArrayReadHandler)
-				if token == "{" && isMapHandler {
-					val, err = p.parseMap(false, cache, &mapHandler)
-					if err != nil {
-						return nil, err
-					}
-				} else if token == "[" && isArrayHandler {
-					val, err = p.parseArray(false, cache, &arrayHandler)
-					if err != nil {
-						return nil, err
-					}
-				} else {
-					parsedVal, err := p.parseVal(false, cache)
-					if err != nil {
-						return nil, err
-					}
-					readHandler, ok := valHandler.(ReadHandler)
-					if !ok {
-						return nil, err
-					}
-					val, err = readHandler.FromRep(parsedVal)
-					if err != nil {
-						return nil, err
-					}
-				}
-			} else {
-				parsedVal, err := p.parseVal(false, cache)
-				if err != nil {
-					return nil, err
-				}
-				val, err = p.base.decode(string(tag), parsedVal)
-				if err != nil {
-					return nil, err
-				}
+			// a verbose tagged value: {"~#tag": rep}, which is the only entry
+			if !first {
+				return nil, misplacedTagError(tag)
+			}
+			val, err := p.parseTagged(string(tag), cache)
+			if err != nil {
+				return nil, err
+			}
+			// advance to read end of object
+			err = p.expectEnd(tag, string(endRune))
+			if err != nil {
+				return nil, err
 			}
-			// advance to read end of array or object
-			p.nextToken()
 			return val, nil
 		} else {
-			p.nextToken()
+			_, err = p.nextToken()
+			if err != nil {
+				return nil, err
+			}
 			val, err := p.parseVal(false, cache)
 			if err != nil {
 				return nil, err
@@ -172,96 +197,109 @@ func (p JsonParser) parseMapUntilToken(asMapKey bool, cache ReadCache, handler *
 	return mr.Complete(mb), nil
 }
 
-func (p JsonParser) parseArray(ignored bool, cache ReadCache, handler *ArrayReadHandler) (interface{}, error) {
-	if p.nextToken() != "]" {
-		firstVal, err := p.parseVal(false, cache)
+func (p *JsonParser) parseArray(ignored bool, cache ReadCache, handler *ArrayReadHandler) (interface{}, error) {
+	var arrayReader ArrayReader
+	if handler != nil {
+		arrayReader = handler.arrayReader
+	} else {
+		arrayReader = p.base.arrayBuilder
+	}
+
+	token, err := p.nextToken()
+	if err != nil {
+		return nil, err
+	}
+	if tokenEquals(token, "]") {
+		// Make an empty collection, using handler's array reader, if present
+		return arrayReader.Complete(arrayReader.Init(0)), nil
+	}
+
+	firstVal, err := p.parseVal(false, cache)
+	if err != nil {
+		return nil, err
+	}
+	if firstVal != nil {
+		tagTag, isTag := firstVal.(Tag)
+
+		if firstVal == constants.MAP_AS_ARRAY {
+			// if the same, build a map with rest array contents
+			return p.parseMapUntilToken(false, cache, nil, ']')
+		} else if isTag {
+			val, err := p.parseTagged(string(tagTag), cache)
+			if err != nil {
+				return nil, err
+			}
+			// advance past the end of the array
+			err = p.expectEnd(tagTag, "]")
+			if err != nil {
+				return nil, err
+			}
+			return val, nil
+		}
+	}
+
+	// process array without special decoding or interpretation
+	ab := arrayReader.Init(0)
+	ab = arrayReader.Add(ab, firstVal)
+	for {
+		token, err := p.nextToken()
 		if err != nil {
 			return nil, err
 		}
-		if firstVal != nil {
-			tagTag, isTag := firstVal.(Tag)
-
-			if firstVal == constants.MAP_AS_ARRAY {
-				// if the same, build a map with rest array contents
-				return p.parseMapUntilToken(false, cache, nil, ']')
-			} else if isTag {
-				tag := string(tagTag)
-				valHandler, err := p.base.readHandlerMap.lookupHandler(tag)
-				p.nextToken()
-
-				var val interface{}
-				if err == nil {
-					mapHandler, isMapHandler := valHandler.(MapReadHandler)
-					arrayHandler, isArrayHandler := valHandler.(ArrayReadHandler)
-
-					currentToken := p.currentToken()
-
-					tokenDelim, isDelim := currentToken.(json.Delim)
-					currentTokenString := string(tokenDelim)
-					if isDelim && currentTokenString == "{" && isMapHandler {
-						val, err = p.parseMap(false, cache, &mapHandler)
-						if err != nil {
-							return nil, err
-						}
-					} else if isDelim && currentTokenString == "[" && isArrayHandler {
-						val, err = p.parseArray(false, cache, &arrayHandler)
-						if err != nil {
-							return nil, err
-						}
-					} else {
-						// read value and decode normally
-						handler, _ := valHandler.(ReadHandler)
-						parsedVal, err := p.parseVal(false, cache)
-
-						if err != nil {
-							return nil, err
-						}
-						val, err = handler.FromRep(parsedVal)
-						if err != nil {
-							return nil, err
-						}
-					}
-				} else {
-					// default decode
-					parsedVal, err := p.parseVal(false, cache)
-					if err != nil {
-						return nil, err
-					}
-					val, err = p.base.decode(tag, parsedVal)
-				}
-				// advance past the end of the object or array
-				p.nextToken()
-				return val, nil
-			}
+		if tokenEquals(token, "]") {
+			break
 		}
-
-		// process array without special decoding or interpretation
-		var arrayReader ArrayReader
-		if handler != nil {
-			arrayReader = handler.arrayReader
-		} else {
-			arrayReader = p.base.arrayBuilder
+		nextVal, err := p.parseVal(false, cache)
+		if err != nil {
+			return nil, err
 		}
+		ab = arrayReader.Add(ab, nextVal)
+	}
+	return completeArray(arrayReader, ab)
+}
 
-		ab := arrayReader.Init(0)
-		ab = arrayReader.Add(ab, firstVal)
-		for !tokenEquals(p.nextToken(), "]") {
-			nextVal, err := p.parseVal(false, cache)
-			if err == nil {
-				ab = arrayReader.Add(ab, nextVal)
-			}
+// parseTagged reads the representation following a tag, and decodes it using
+// the handler registered for the tag
+func (p *JsonParser) parseTagged(tag string, cache ReadCache) (interface{}, error) {
+	// advance to read the representation
+	token, err := p.nextToken()
+	if err != nil {
+		return nil, err
+	}
+	if tokenEquals(token, "]") || tokenEquals(token, "}") {
+		return nil, misplacedTagError(Tag(tag))
+	}
+
+	valHandler, err := p.base.readHandlerMap.lookupHandler(tag)
+	if err != nil {
+		// default decode
+		parsedVal, err := p.parseVal(false, cache)
+		if err != nil {
+			return nil, err
 		}
-		completeArray := arrayReader.Complete(ab)
-		return completeArray, nil
+		return p.base.decode(tag, parsedVal)
 	}
-	// Make an empty collection, using handler's array reader, if present
-	var arrayReader ArrayReader
-	if handler != nil {
-		arrayReader = handler.arrayReader
-	} else {
-		arrayReader = p.base.arrayBuilder
+
+	mapHandler, isMapHandler := valHandler.(MapReadHandler)
+	arrayHandler, isArrayHandler := valHandler.(ArrayReadHandler)
+
+	currentToken := p.currentToken()
+	if tokenEquals(currentToken, "{") && isMapHandler {
+		return p.parseMap(false, cache, &mapHandler)
+	} else if tokenEquals(currentToken, "[") && isArrayHandler {
+		return p.parseArray(false, cache, &arrayHandler)
+	}
+
+	// read value and decode normally
+	parsedVal, err := p.parseVal(false, cache)
+	if err != nil {
+		return nil, err
+	}
+	readHandler, ok := valHandler.(ReadHandler)
+	if !ok {
+		return nil, fmt.Errorf("Could not decode %s because the handler is not a ReadHandler", tag)
 	}
-	return arrayReader.Complete(arrayReader.Init(0)), nil
+	return readHandler.FromRep(parsedVal)
 }
 
 func tokenEquals(token json.Token, str string) bool {
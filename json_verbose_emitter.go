@@ -0,0 +1,61 @@
+package transit_go
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/nedap/transit-go/constants"
+)
+
+// JsonVerboseEmitter writes the human readable form of transit JSON: maps are
+// written as JSON objects and tagged values as objects with a single "~#tag" key.
+type JsonVerboseEmitter struct {
+	*JsonEmitter
+}
+
+func NewJsonVerboseEmitter(w io.Writer, writeHandlerMap WriteHandlerMap) Emitter {
+	return newJsonVerboseEmitter(w, newWriteHandlers(writeHandlerMap))
+}
+
+func newJsonVerboseEmitter(w io.Writer, handlers *writeHandlers) Emitter {
+	jsonEmitter := &JsonEmitter{out: w, writer: new(bytes.Buffer)}
+	verboseEmitter := &JsonVerboseEmitter{JsonEmitter: jsonEmitter}
+	baseEmitter := baseEmitter{handlers: handlers, emitter: verboseEmitter}
+	jsonEmitter.base = baseEmitter
+	return verboseEmitter
+}
+
+func (j *JsonVerboseEmitter) emitTagged(t string, obj interface{}, ignored bool, cache WriteCache) error {
+	err := j.emitMapStart(1)
+	if err != nil {
+		return err
+	}
+	err = j.emitString(constants.ESC_TAG, t, "", true, cache)
+	if err != nil {
+		return err
+	}
+	err = j.base.marshal(obj, false, cache)
+	if err != nil {
+		return err
+	}
+	return j.emitMapEnd()
+}
+
+func (j *JsonVerboseEmitter) emitActualMap(entries mapEntries, ignored bool, cache WriteCache) error {
+	err := j.emitMapStart(len(entries))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = j.base.marshal(entry.key, true, cache)
+		if err != nil {
+			return err
+		}
+		err = j.base.marshal(entry.value, false, cache)
+		if err != nil {
+			return err
+		}
+	}
+	return j.emitMapEnd()
+}
@@ -0,0 +1,185 @@
+package transit_go
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON Verbose Writer", func() {
+	var buffer bytes.Buffer
+	writer := NewJSONVerboseWriter(&buffer)
+
+	AfterEach(func() {
+		buffer.Reset()
+	})
+
+	It("marshals nil", func() {
+		result := write(writer, nil)
+		Expect(result).To(Equal("{\"~#'\":null}"))
+	})
+
+	It("marshals strings", func() {
+		result := write(writer, "a string")
+		Expect(result).To(Equal("{\"~#'\":\"a string\"}"))
+	})
+
+	It("marshals integers", func() {
+		result := write(writer, 24)
+		Expect(result).To(Equal("{\"~#'\":24}"))
+	})
+
+	It("marshals times as ISO 8601 strings", func() {
+		t := time.Unix(0, 1456231033010*int64(time.Millisecond))
+		result := write(writer, t)
+		Expect(result).To(Equal("{\"~#'\":\"~t2016-02-23T12:37:13.010Z\"}"))
+	})
+
+	It("marshals times with millisecond precision in UTC", func() {
+		t := time.Date(2016, 2, 23, 13, 37, 13, 123456789, time.FixedZone("CET", 3600))
+		result := write(writer, t)
+		Expect(result).To(Equal("{\"~#'\":\"~t2016-02-23T12:37:13.123Z\"}"))
+	})
+
+	It("marshals a simple int array", func() {
+		result := write(writer, []int{1, 2, 3, 4})
+		Expect(result).To(Equal("[1,2,3,4]"))
+	})
+
+	It("marshals an empty map", func() {
+		result := write(writer, map[string]string{})
+		Expect(result).To(Equal("{}"))
+	})
+
+	It("marshals maps as objects", func() {
+		result := write(writer, map[string]int{"key": 12})
+		Expect(result).To(Equal("{\"key\":12}"))
+	})
+
+	It("marshals non-string keys as escaped strings", func() {
+		result := write(writer, map[int]bool{1: true})
+		Expect(result).To(Equal("{\"~i1\":true}"))
+	})
+
+	It("marshals nested maps", func() {
+		m := map[string]interface{}{
+			"resource": map[string]interface{}{"id": 3},
+		}
+		result := write(writer, m)
+		Expect(result).To(Equal("{\"resource\":{\"id\":3}}"))
+	})
+
+	It("does not cache keys", func() {
+		m := map[string]string{"name": "JW"}
+		result := write(writer, []map[string]string{m, m})
+		Expect(result).To(Equal("[{\"name\":\"JW\"},{\"name\":\"JW\"}]"))
+	})
+
+	It("marshals tagged values as single entry objects", func() {
+		result := write(writer, TaggedValue{Tag: "point", Rep: []int{1, 2}})
+		Expect(result).To(Equal("{\"~#point\":[1,2]}"))
+	})
+
+	It("marshals maps with composite keys as cmaps", func() {
+		result := write(writer, NewMap([]int{1, 2}, "a"))
+		Expect(result).To(Equal("{\"~#cmap\":[[1,2],\"a\"]}"))
+	})
+})
+
+var _ = Describe("JSON Verbose Reader", func() {
+	var readString = func(str string) interface{} {
+		reader := NewJSONReader(bytes.NewBufferString(str))
+		result, err := reader.Read()
+		Expect(err).To(BeNil())
+		return result
+	}
+
+	var mapAsStringKeyed = func(m interface{}) map[interface{}]interface{} {
+		result := make(map[interface{}]interface{})
+		m.(Map).Range(func(key, v interface{}) bool {
+			result[key] = v
+			return true
+		})
+		return result
+	}
+
+	It("reads quoted values", func() {
+		Expect(readString("{\"~#'\":null}")).To(BeNil())
+		Expect(readString("{\"~#'\":\"a string\"}")).To(Equal("a string"))
+		Expect(readString("{\"~#'\":24}")).To(Equal(24))
+	})
+
+	It("reads ISO 8601 timestamps", func() {
+		result := readString("{\"~#'\":\"~t2016-02-23T12:37:13.010Z\"}")
+		t := time.Unix(0, 1456231033010*int64(time.Millisecond))
+		Expect(result.(time.Time).Equal(t)).To(BeTrue())
+	})
+
+	It("reads tagged sets", func() {
+		result := readString("{\"~#set\":[\"a\",\"b\"]}")
+		set, ok := result.(Set)
+		Expect(ok).To(BeTrue())
+		Expect(set.Len()).To(Equal(2))
+		Expect(set.Contains("a")).To(BeTrue())
+		Expect(set.Contains("b")).To(BeTrue())
+	})
+
+	It("reads empty tagged sets", func() {
+		result := readString("{\"~#set\":[]}")
+		set, ok := result.(Set)
+		Expect(ok).To(BeTrue())
+		Expect(set.Len()).To(Equal(0))
+	})
+
+	It("reads plain objects as maps", func() {
+		result := mapAsStringKeyed(readString("{\"key\":12,\"~i1\":\"hello\"}"))
+		Expect(result).To(Equal(map[interface{}]interface{}{"key": 12, 1: "hello"}))
+	})
+
+	It("reads escaped keys", func() {
+		result := mapAsStringKeyed(readString("{\"~~tilde\":1,\"~^caret\":2}"))
+		Expect(result).To(Equal(map[interface{}]interface{}{"~tilde": 1, "^caret": 2}))
+	})
+
+	It("reads encoded keys", func() {
+		result := mapAsStringKeyed(readString("{\"~t2016-02-23T12:37:13.010Z\":\"then\"}"))
+		Expect(len(result)).To(Equal(1))
+		for k, v := range result {
+			t := time.Unix(0, 1456231033010*int64(time.Millisecond))
+			Expect(k.(time.Time).Equal(t)).To(BeTrue())
+			Expect(v).To(Equal("then"))
+		}
+	})
+
+	It("reads nested tagged values", func() {
+		result := mapAsStringKeyed(readString("{\"points\":[{\"~#point\":[1,2]},{\"~#point\":[3,4]}]}"))
+		Expect(result["points"]).To(Equal([]interface{}{
+			TaggedValue{Tag: "point", Rep: []interface{}{1, 2}},
+			TaggedValue{Tag: "point", Rep: []interface{}{3, 4}},
+		}))
+	})
+
+	It("returns an error for a tag key alongside other entries", func() {
+		for _, str := range []string{"{\"a\":1,\"~#set\":[1]}", "{\"~#set\":[1],\"a\":1}", "[\"~#set\",[1],2]", "[\"~#set\"]"} {
+			_, err := NewJSONReader(bytes.NewBufferString(str)).Read()
+			Expect(err).To(MatchError("Tag set is not the first of an array of two or the only key of a map"), str)
+		}
+	})
+
+	It("reads what the verbose writer writes", func() {
+		var buffer bytes.Buffer
+		writer := NewJSONVerboseWriter(&buffer)
+		m := map[string]interface{}{"id": 12, "names": []string{"a", "b"}, "nested": map[string]int{"x": 1}}
+		err := writer.Write(m)
+		Expect(err).To(BeNil())
+
+		read, err := NewJSONReader(&buffer).Read()
+		Expect(err).To(BeNil())
+		result := mapAsStringKeyed(read)
+		Expect(result["id"]).To(Equal(12))
+		Expect(result["names"]).To(Equal([]interface{}{"a", "b"}))
+		Expect(mapAsStringKeyed(result["nested"])).To(Equal(map[interface{}]interface{}{"x": 1}))
+	})
+})
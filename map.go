@@ -0,0 +1,192 @@
+package transit_go
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Map is an immutable map that compares its keys by value, so composite keys like
+// slices, maps, sets and tagged values can be looked up by an equal value. It keeps
+// its entries in insertion order. Transit maps are read as a Map by default.
+//
+// Map is copy-on-write, it does not share structure between versions: Assoc and
+// Dissoc copy the entries into a new Map, in time proportional to its size, and
+// leave the original unchanged. The zero value is an empty map.
+//
+// Float keys are compared with ==, so -0.0 finds the entry of 0.0. NaN is not
+// equal to itself in Go, but a NaN key is equal to any other NaN key here, so a
+// map holds at most one NaN key and Get finds it.
+type Map struct {
+	entries []mapEntry
+	index   map[uint64][]int
+}
+
+// NewMap returns a map of the given keys and values, which alternate
+func NewMap(keyvals ...interface{}) Map {
+	if len(keyvals)%2 != 0 {
+		panic("NewMap expects an even number of keys and values")
+	}
+	m := &Map{}
+	for i := 0; i < len(keyvals); i += 2 {
+		m.put(keyvals[i], keyvals[i+1])
+	}
+	return *m
+}
+
+// find returns the position of the entry of key, or -1
+func (m Map) find(key interface{}, hash uint64) int {
+	for _, i := range m.index[hash] {
+		if valueEquals(m.entries[i].key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// put adds or replaces an entry in place, it is only used while building a map
+func (m *Map) put(key, value interface{}) {
+	hash := valueHash(key)
+	if i := m.find(key, hash); i >= 0 {
+		m.entries[i].value = value
+		return
+	}
+	if m.index == nil {
+		m.index = make(map[uint64][]int)
+	}
+	m.index[hash] = append(m.index[hash], len(m.entries))
+	m.entries = append(m.entries, mapEntry{key: key, value: value})
+}
+
+// Get returns the value of key, and whether the map contains it
+func (m Map) Get(key interface{}) (interface{}, bool) {
+	if i := m.find(key, valueHash(key)); i >= 0 {
+		return m.entries[i].value, true
+	}
+	return nil, false
+}
+
+// Assoc returns a copy of the map with key set to value. Building a map with Assoc
+// takes quadratic time, to add many entries use NewMap, or Transient.
+func (m Map) Assoc(key, value interface{}) Map {
+	result := Map{
+		entries: make([]mapEntry, len(m.entries), len(m.entries)+1),
+		index:   make(map[uint64][]int, len(m.index)+1),
+	}
+	copy(result.entries, m.entries)
+	for hash, positions := range m.index {
+		result.index[hash] = positions
+	}
+
+	hash := valueHash(key)
+	if i := m.find(key, hash); i >= 0 {
+		result.entries[i].value = value
+		return result
+	}
+	// copy the bucket, so appending to it does not change the original
+	positions := make([]int, len(m.index[hash]), len(m.index[hash])+1)
+	copy(positions, m.index[hash])
+	result.index[hash] = append(positions, len(result.entries))
+	result.entries = append(result.entries, mapEntry{key: key, value: value})
+	return result
+}
+
+// Dissoc returns a map without key
+func (m Map) Dissoc(key interface{}) Map {
+	i := m.find(key, valueHash(key))
+	if i < 0 {
+		return m
+	}
+	result := &Map{}
+	for j, entry := range m.entries {
+		if j != i {
+			result.put(entry.key, entry.value)
+		}
+	}
+	return *result
+}
+
+// Transient returns a TransientMap with the entries of m, which adds entries in
+// place. m itself is unchanged.
+func (m Map) Transient() *TransientMap {
+	t := &TransientMap{m: Map{
+		entries: make([]mapEntry, len(m.entries)),
+		index:   make(map[uint64][]int, len(m.index)),
+	}}
+	copy(t.m.entries, m.entries)
+	for hash, positions := range m.index {
+		t.m.index[hash] = append([]int(nil), positions...)
+	}
+	return t
+}
+
+// TransientMap builds a Map by changing it in place, so adding an entry takes
+// constant time. It cannot be changed after Persistent returned the Map.
+type TransientMap struct {
+	m          Map
+	persistent bool
+}
+
+// Assoc sets key to value
+func (t *TransientMap) Assoc(key, value interface{}) *TransientMap {
+	if t.persistent {
+		panic("TransientMap used after Persistent")
+	}
+	t.m.put(key, value)
+	return t
+}
+
+// Persistent returns the Map that was built
+func (t *TransientMap) Persistent() Map {
+	t.persistent = true
+	return t.m
+}
+
+// Keys returns the keys of the map in insertion order
+func (m Map) Keys() []interface{} {
+	keys := make([]interface{}, len(m.entries))
+	for i, entry := range m.entries {
+		keys[i] = entry.key
+	}
+	return keys
+}
+
+func (m Map) Len() int {
+	return len(m.entries)
+}
+
+// Range calls f for each entry in insertion order, until f returns false
+func (m Map) Range(f func(key, value interface{}) bool) {
+	for _, entry := range m.entries {
+		if !f(entry.key, entry.value) {
+			return
+		}
+	}
+}
+
+// Equal reports whether both maps have equal keys with equal values, regardless
+// of their order
+func (m Map) Equal(other Map) bool {
+	if m.Len() != other.Len() {
+		return false
+	}
+	for _, entry := range m.entries {
+		value, ok := other.Get(entry.key)
+		if !ok || !valueEquals(entry.value, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Map) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("map[")
+	for i, entry := range m.entries {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		fmt.Fprintf(&buf, "%v:%v", entry.key, entry.value)
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
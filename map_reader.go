@@ -6,18 +6,19 @@ type MapReader interface {
 	Complete(interface{}) interface{}
 }
 
+// MapBuilder builds a Map
 type MapBuilder struct{}
 
 func (b MapBuilder) Init() interface{} {
-	return make(map[*MapKey]interface{})
+	return &Map{}
 }
 
 func (b MapBuilder) Add(m interface{}, key, val interface{}) interface{} {
-	actualMap, _ := m.(map[*MapKey]interface{})
-	actualMap[newMapKey(key)] = val
+	actualMap, _ := m.(*Map)
+	actualMap.put(key, val)
 	return actualMap
 }
 
 func (b MapBuilder) Complete(m interface{}) interface{} {
-	return m
+	return *(m.(*Map))
 }
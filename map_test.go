@@ -0,0 +1,155 @@
+package transit_go
+
+import (
+	"math"
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Map", func() {
+	var writeToString = func(obj interface{}) string {
+		data, err := Marshal(obj)
+		Expect(err).To(BeNil())
+		return string(data)
+	}
+
+	var readFromString = func(str string) interface{} {
+		var v interface{}
+		Expect(Unmarshal([]byte(str), &v)).To(Succeed())
+		return v
+	}
+
+	It("gets values by key", func() {
+		m := NewMap("a", 1, Keyword("b"), 2)
+		v, ok := m.Get("a")
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal(1))
+
+		_, ok = m.Get("b")
+		Expect(ok).To(BeFalse())
+		v, _ = m.Get(Keyword("b"))
+		Expect(v).To(Equal(2))
+	})
+
+	It("finds composite keys by value", func() {
+		m := NewMap(
+			[]interface{}{1, 2}, "slice",
+			NewMap("x", 1), "map",
+			NewSetFrom([]interface{}{"s"}), "set",
+			TaggedValue{Tag: "point", Rep: []interface{}{1, 2}}, "tagged",
+		)
+
+		v, _ := m.Get([]int{1, 2})
+		Expect(v).To(Equal("slice"))
+		v, _ = m.Get(NewMap("x", int64(1)))
+		Expect(v).To(Equal("map"))
+		v, _ = m.Get(NewSetFrom([]interface{}{"s"}))
+		Expect(v).To(Equal("set"))
+		v, _ = m.Get(TaggedValue{Tag: "point", Rep: []interface{}{1, 2}})
+		Expect(v).To(Equal("tagged"))
+
+		_, ok := m.Get(TaggedValue{Tag: "other", Rep: []interface{}{1, 2}})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("compares numbers by value", func() {
+		m := NewMap(1, "int", big.NewInt(2), "big")
+		v, _ := m.Get(uint8(1))
+		Expect(v).To(Equal("int"))
+		v, _ = m.Get(big.NewInt(2))
+		Expect(v).To(Equal("big"))
+	})
+
+	It("compares byte slices to other slices in either order", func() {
+		Expect(valueEquals([]byte{1}, []interface{}{1})).To(BeTrue())
+		Expect(valueEquals([]interface{}{1}, []byte{1})).To(BeTrue())
+
+		v, _ := NewMap([]byte{1}, "bytes").Get([]interface{}{1})
+		Expect(v).To(Equal("bytes"))
+		v, _ = NewMap([]interface{}{1}, "slice").Get([]byte{1})
+		Expect(v).To(Equal("slice"))
+	})
+
+	It("finds -0.0 with 0.0 and NaN with NaN", func() {
+		m := NewMap(0.0, "zero", math.NaN(), "nan")
+		v, _ := m.Get(math.Copysign(0, -1))
+		Expect(v).To(Equal("zero"))
+		v, _ = m.Get(math.NaN())
+		Expect(v).To(Equal("nan"))
+		Expect(m.Assoc(math.NaN(), "again").Len()).To(Equal(2))
+		Expect(NewSetFrom([]interface{}{0.0, math.Copysign(0, -1)}).Len()).To(Equal(1))
+	})
+
+	It("leaves the original unchanged on Assoc and Dissoc", func() {
+		m := NewMap("a", 1)
+		m2 := m.Assoc("b", 2)
+		m3 := m2.Assoc("a", 3)
+		m4 := m3.Dissoc("b")
+
+		Expect(m.Len()).To(Equal(1))
+		Expect(m2.Len()).To(Equal(2))
+		v, _ := m2.Get("a")
+		Expect(v).To(Equal(1))
+		v, _ = m3.Get("a")
+		Expect(v).To(Equal(3))
+		Expect(m4.Keys()).To(Equal([]interface{}{"a"}))
+		_, ok := m3.Get("b")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("adds entries in place to a transient map", func() {
+		m := NewMap("a", 1)
+		t := m.Transient()
+		for i := 0; i < 1000; i++ {
+			t.Assoc(i, i)
+		}
+		t.Assoc("a", 2)
+		result := t.Persistent()
+
+		Expect(result.Len()).To(Equal(1001))
+		v, _ := result.Get("a")
+		Expect(v).To(Equal(2))
+		v, _ = m.Get("a")
+		Expect(v).To(Equal(1))
+		Expect(m.Len()).To(Equal(1))
+		Expect(func() { t.Assoc("b", 1) }).To(Panic())
+	})
+
+	It("ranges over entries in insertion order", func() {
+		m := NewMap("c", 1, "a", 2).Assoc("b", 3)
+		var keys []interface{}
+		m.Range(func(key, value interface{}) bool {
+			keys = append(keys, key)
+			return len(keys) < 2
+		})
+		Expect(keys).To(Equal([]interface{}{"c", "a"}))
+		Expect(m.Keys()).To(Equal([]interface{}{"c", "a", "b"}))
+	})
+
+	It("is equal to a map with the same entries in another order", func() {
+		Expect(NewMap("a", 1, "b", []int{1}).Equal(NewMap("b", []interface{}{1}, "a", 1))).To(BeTrue())
+		Expect(NewMap("a", 1).Equal(NewMap("a", 2))).To(BeFalse())
+		Expect(NewMap().Equal(Map{})).To(BeTrue())
+	})
+
+	It("hashes equal values the same", func() {
+		Expect(valueHash(NewMap("a", 1, "b", 2))).To(Equal(valueHash(NewMap("b", 2, "a", 1))))
+		Expect(valueHash([]int{1, 2})).To(Equal(valueHash([]interface{}{int64(1), 2})))
+		Expect(valueHash(Keyword("a"))).NotTo(Equal(valueHash("a")))
+	})
+
+	It("is written as a map, or a cmap for composite keys", func() {
+		Expect(writeToString(NewMap("b", 1, "a", 2))).To(Equal("[\"^ \",\"b\",1,\"a\",2]"))
+		Expect(writeToString(NewMap([]int{1}, 2))).To(Equal("[\"~#cmap\",[[1],2]]"))
+	})
+
+	It("is read and written back", func() {
+		str := "[\"~#cmap\",[[\"^ \",\"a\",1],\"map key\",[1,2],\"array key\"]]"
+		m := readFromString(str).(Map)
+		v, _ := m.Get(NewMap("a", 1))
+		Expect(v).To(Equal("map key"))
+		Expect(writeToString(m)).To(Equal(str))
+	})
+})
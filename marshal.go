@@ -0,0 +1,35 @@
+package transit_go
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Marshal returns the transit encoding of v, by default as transit JSON.
+func Marshal(v interface{}, opts ...Option) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := NewEncoder(&buffer, opts...).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal decodes the single transit value in data, by default transit JSON,
+// and stores it in the value pointed to by v as described for Decoder.Decode.
+func Unmarshal(data []byte, v interface{}, opts ...Option) error {
+	decoder := NewDecoder(bytes.NewReader(data), opts...)
+	err := decoder.Decode(v)
+	if err == io.EOF {
+		// data has to hold a value, so an end before it is unexpected
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if !decoder.parser.atEnd() {
+		return fmt.Errorf("Unexpected data after the transit value")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package transit_go
+
+import (
+	"io"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type marshalPoint struct {
+	X, Y int
+}
+
+var _ = Describe("Marshal", func() {
+	It("marshals to transit JSON by default", func() {
+		data, err := Marshal(map[string]int{"key": 1})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"^ \",\"key\",1]"))
+	})
+
+	It("marshals to JSON-Verbose", func() {
+		data, err := Marshal(map[string]int{"key": 1}, WithFormat(FormatJSONVerbose))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("{\"key\":1}"))
+	})
+
+	It("caches map keys unless caching is turned off", func() {
+		m := map[string]int{"name": 1}
+
+		data, err := Marshal([]interface{}{m, m})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[[\"^ \",\"name\",1],[\"^ \",\"^0\",1]]"))
+
+		data, err = Marshal([]interface{}{m, m}, WithCaching(false))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[[\"^ \",\"name\",1],[\"^ \",\"name\",1]]"))
+	})
+
+	It("writes maps and sets in canonical order", func() {
+		m := map[string]interface{}{
+			"zebra": 1, "apple": 2, "mango": 3, "kiwi": 4, "banana": 5,
+			"nested": map[Keyword]int{"b": 1, "a": 2},
+			"set":    NewSetFrom([]interface{}{"c", "a", "b"}),
+		}
+		expected := "[\"^ \",\"apple\",2,\"banana\",5,\"kiwi\",4,\"mango\",3,\"nested\",[\"^ \",\"~:a\",2,\"~:b\",1],\"set\",[\"~#set\",[\"a\",\"b\",\"c\"]],\"zebra\",1]"
+		for i := 0; i < 10; i++ {
+			data, err := Marshal(m, WithCanonicalOrder(true))
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(expected))
+		}
+	})
+
+	It("writes composite keys in canonical order", func() {
+		m := NewMap([]int{2}, "two", []int{1}, "one", []int{3}, "three")
+		data, err := Marshal(m, WithCanonicalOrder(true))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~#cmap\",[[1],\"one\",[2],\"two\",[3],\"three\"]]"))
+	})
+
+	It("writes deeply nested composite keys in canonical order", func() {
+		m := NewMap("a", 0)
+		for i := 1; i < 40; i++ {
+			m = NewMap(m, i)
+		}
+		expected, err := Marshal(m, WithCaching(false))
+		Expect(err).To(BeNil())
+		data, err := Marshal(m, WithCaching(false), WithCanonicalOrder(true))
+		Expect(err).To(BeNil())
+		Expect(data).To(Equal(expected))
+	})
+
+	It("returns the error of a key it cannot encode in canonical order", func() {
+		_, err := Marshal(NewSetFrom([]interface{}{1, make(chan int)}), WithCanonicalOrder(true))
+		Expect(err).To(MatchError(ContainSubstring("No handler found for type chan int")))
+	})
+
+	It("writes the same MessagePack in canonical order", func() {
+		m := map[string]int{}
+		for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			m[key] = len(key)
+		}
+		first, err := Marshal(m, WithFormat(FormatMsgpack), WithCanonicalOrder(true))
+		Expect(err).To(BeNil())
+		for i := 0; i < 10; i++ {
+			data, err := Marshal(m, WithFormat(FormatMsgpack), WithCanonicalOrder(true))
+			Expect(err).To(BeNil())
+			Expect(data).To(Equal(first))
+		}
+	})
+
+	It("uses custom write handlers", func() {
+		handlers := WriteHandlerMap{
+			reflect.TypeOf(marshalPoint{}): WriteHandler{
+				Name: "Point Write Handler",
+				Tag:  func(obj interface{}) string { return "point" },
+				Rep: func(obj interface{}) interface{} {
+					p := obj.(marshalPoint)
+					return []int{p.X, p.Y}
+				},
+				StringRep: func(obj interface{}) *string { return nil },
+			},
+		}
+
+		data, err := Marshal(marshalPoint{1, 2}, WithWriteHandlers(handlers))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~#point\",[1,2]]"))
+	})
+})
+
+var _ = Describe("Unmarshal", func() {
+	It("unmarshals transit JSON by default", func() {
+		var v interface{}
+		Expect(Unmarshal([]byte("[\"~#'\",\"~:hello\"]"), &v)).To(Succeed())
+		Expect(v).To(Equal(Keyword("hello")))
+	})
+
+	It("unmarshals MessagePack", func() {
+		data, err := Marshal([]string{"a", "b"}, WithFormat(FormatMsgpack))
+		Expect(err).To(BeNil())
+
+		var v interface{}
+		Expect(Unmarshal(data, &v, WithFormat(FormatMsgpack))).To(Succeed())
+		Expect(v).To(Equal([]interface{}{"a", "b"}))
+	})
+
+	It("uses custom read handlers", func() {
+		handlers := ReadHandlerMap{
+			"point": ReadHandler{
+				Name: "Point Read Handler",
+				FromRep: func(rep interface{}) (interface{}, error) {
+					coords := rep.([]interface{})
+					return marshalPoint{coords[0].(int), coords[1].(int)}, nil
+				},
+			},
+		}
+
+		var v interface{}
+		Expect(Unmarshal([]byte("[\"~#point\",[1,2]]"), &v, WithReadHandlers(handlers))).To(Succeed())
+		Expect(v).To(Equal(marshalPoint{1, 2}))
+	})
+
+	It("returns an error for trailing data", func() {
+		var v interface{}
+		Expect(Unmarshal([]byte("[\"~#'\",1] [\"~#'\",2]"), &v)).NotTo(Succeed())
+		for _, str := range []string{"1]", "[1]]", "[1]}", "1 2"} {
+			Expect(Unmarshal([]byte(str), &v)).To(MatchError("Unexpected data after the transit value"), str)
+		}
+		Expect(Unmarshal([]byte("1 "), &v)).To(Succeed())
+		Expect(Unmarshal([]byte{0x01, 0x02}, &v, WithFormat(FormatMsgpack))).To(MatchError("Unexpected data after the transit value"))
+	})
+
+	It("returns an error for empty input", func() {
+		var v interface{}
+		Expect(Unmarshal([]byte(""), &v)).To(Equal(io.ErrUnexpectedEOF))
+		Expect(Unmarshal([]byte(" \n"), &v)).To(Equal(io.ErrUnexpectedEOF))
+		Expect(Unmarshal([]byte{}, &v, WithFormat(FormatMsgpack))).To(Equal(io.ErrUnexpectedEOF))
+	})
+
+	It("returns an error for a lone closing delimiter", func() {
+		var v interface{}
+		for _, str := range []string{"]", "}"} {
+			err := Unmarshal([]byte(str), &v)
+			Expect(err).NotTo(BeNil(), str)
+			Expect(err).NotTo(Equal(io.EOF), str)
+		}
+	})
+})
@@ -0,0 +1,46 @@
+package transit_go
+
+// TransitMarshaler is implemented by types that write themselves as a transit
+// value: the tag and the representation the tag is written with. It takes
+// precedence over the handlers in a WriteHandlerMap.
+//
+// A representation that is a string can be written as "~<tag><rep>" (and be used
+// as a map key) when the tag is a single character; other tags are written as a
+// tagged value ["~#<tag>", rep].
+type TransitMarshaler interface {
+	MarshalTransit() (tag string, rep interface{}, err error)
+}
+
+// TransitUnmarshaler is implemented by types that read themselves from a transit
+// value when they are the target of Decode or Unmarshal. Values with a tag that
+// has no ReadHandler arrive with that tag and their representation. Other values
+// arrive as they were read, with the tag they would be written with, like "s"
+// for a string.
+type TransitUnmarshaler interface {
+	UnmarshalTransit(tag string, rep interface{}) error
+}
+
+// marshalerWriteHandler writes the result of MarshalTransit
+func marshalerWriteHandler(tag string, rep interface{}) WriteHandler {
+	return WriteHandler{
+		Name: "TransitMarshaler Write Handler",
+		Tag:  func(obj interface{}) string { return tag },
+		Rep: func(obj interface{}) interface{} {
+			return rep
+		},
+		StringRep: func(obj interface{}) *string {
+			if str, ok := rep.(string); ok {
+				return &str
+			}
+			return nil
+		},
+	}
+}
+
+// unmarshalTransit passes a value as returned by the parser to a TransitUnmarshaler
+func unmarshalTransit(u TransitUnmarshaler, src interface{}) error {
+	if tv, ok := src.(TaggedValue); ok {
+		return u.UnmarshalTransit(tv.Tag, tv.Rep)
+	}
+	return u.UnmarshalTransit(defaultHandlers.GetTag(src), src)
+}
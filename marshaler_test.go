@@ -0,0 +1,184 @@
+package transit_go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type money struct {
+	Amount   int
+	Currency string
+}
+
+func (m money) MarshalTransit() (string, interface{}, error) {
+	if m.Currency == "" {
+		return "", nil, errors.New("money without currency")
+	}
+	return "money", []interface{}{m.Amount, m.Currency}, nil
+}
+
+func (m *money) UnmarshalTransit(tag string, rep interface{}) error {
+	if tag != "money" {
+		return fmt.Errorf("Cannot read money from tag %s", tag)
+	}
+	parts := rep.([]interface{})
+	m.Amount = parts[0].(int)
+	m.Currency = parts[1].(string)
+	return nil
+}
+
+type accountID string
+
+func (id accountID) MarshalTransit() (string, interface{}, error) {
+	return "A", string(id), nil
+}
+
+func (id *accountID) UnmarshalTransit(tag string, rep interface{}) error {
+	str, ok := rep.(string)
+	if !ok {
+		return fmt.Errorf("Cannot read accountID from %v", rep)
+	}
+	*id = accountID(str)
+	return nil
+}
+
+type pointerMarshaler struct {
+	A int
+}
+
+func (p *pointerMarshaler) MarshalTransit() (string, interface{}, error) {
+	return "pm", p.A, nil
+}
+
+type marshalerFunc func() (string, interface{}, error)
+
+func (f marshalerFunc) MarshalTransit() (string, interface{}, error) {
+	return f()
+}
+
+var _ = Describe("TransitMarshaler", func() {
+	It("writes the tag and representation it returns", func() {
+		data, err := Marshal(money{Amount: 12, Currency: "EUR"})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~#money\",[12,\"EUR\"]]"))
+	})
+
+	It("writes single character tags with string representations as strings", func() {
+		data, err := Marshal(map[accountID]int{"NL01": 1})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"^ \",\"~ANL01\",1]"))
+	})
+
+	It("writes struct fields with a pointer receiver MarshalTransit", func() {
+		type holder struct {
+			P *pointerMarshaler
+			N *pointerMarshaler
+		}
+		data, err := Marshal(holder{P: &pointerMarshaler{A: 2}})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"^ \",\"P\",[\"~#pm\",2],\"N\",null]"))
+	})
+
+	It("takes precedence over registered handlers", func() {
+		var buffer bytes.Buffer
+		writer := NewJSONWriterWithHandlers(&buffer, WriteHandlerMap{
+			reflect.TypeOf(money{}): booleanWriteHandler(),
+		})
+		Expect(write(writer, money{Amount: 1, Currency: "EUR"})).To(Equal("[\"~#money\",[1,\"EUR\"]]"))
+	})
+
+	It("writes a Go map or Map returned with the map tag as a map", func() {
+		var mapOf = func(rep interface{}) marshalerFunc {
+			return func() (string, interface{}, error) { return "map", rep, nil }
+		}
+
+		data, err := Marshal(mapOf(map[string]int{"a": 1}))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"^ \",\"a\",1]"))
+
+		data, err = Marshal(mapOf(NewMap(Keyword("a"), 1)))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"^ \",\"~:a\",1]"))
+
+		_, err = Marshal(mapOf([]int{1}))
+		Expect(err).To(MatchError("Cannot emit map when obj is not a map; [1]"))
+	})
+
+	It("writes scalar tags with a string or a representation of their type", func() {
+		var scalar = func(tag string, rep interface{}) marshalerFunc {
+			return func() (string, interface{}, error) { return tag, rep, nil }
+		}
+
+		data, err := Marshal([]interface{}{scalar("i", "42"), scalar("d", float32(1.5)), scalar("?", "t")})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~i42\",1.5,\"~?t\"]"))
+
+		for _, m := range []marshalerFunc{scalar("s", 5), scalar("i", 1.5), scalar("d", 1), scalar("b", 1)} {
+			_, err = Marshal([]interface{}{m})
+			Expect(err).To(MatchError(ContainSubstring("Cannot write")))
+		}
+	})
+
+	It("returns the error of MarshalTransit", func() {
+		_, err := Marshal(money{Amount: 1})
+		Expect(err).To(MatchError("money without currency"))
+	})
+
+	It("returns the error of MarshalTransit for nested values", func() {
+		_, err := Marshal([]interface{}{money{Amount: 1}})
+		Expect(err).To(MatchError("money without currency"))
+		_, err = Marshal(map[string]interface{}{"price": money{Amount: 1}})
+		Expect(err).To(MatchError("money without currency"))
+	})
+})
+
+var _ = Describe("TransitUnmarshaler", func() {
+	It("reads tagged values without a read handler", func() {
+		var m money
+		Expect(Unmarshal([]byte("[\"~#money\",[12,\"EUR\"]]"), &m)).To(Succeed())
+		Expect(m).To(Equal(money{Amount: 12, Currency: "EUR"}))
+	})
+
+	It("reads values nested in structs, slices and maps", func() {
+		var account struct {
+			Balance money
+			History []*money
+			ByID    map[string]money
+		}
+		data, err := Marshal(map[string]interface{}{
+			"balance": money{Amount: 3, Currency: "EUR"},
+			"history": []interface{}{money{Amount: 1, Currency: "EUR"}},
+			"byID":    map[string]interface{}{"x": money{Amount: 2, Currency: "USD"}},
+		})
+		Expect(err).To(BeNil())
+
+		Expect(Unmarshal(data, &account)).To(Succeed())
+		Expect(account.Balance).To(Equal(money{Amount: 3, Currency: "EUR"}))
+		Expect(account.History).To(Equal([]*money{{Amount: 1, Currency: "EUR"}}))
+		Expect(account.ByID).To(Equal(map[string]money{"x": {Amount: 2, Currency: "USD"}}))
+	})
+
+	It("receives untagged values with the tag they are written with", func() {
+		var m money
+		err := Unmarshal([]byte("[\"~#'\",\"plain\"]"), &m)
+		Expect(err).To(MatchError("Cannot read money from tag s"))
+
+		var id accountID
+		Expect(Unmarshal([]byte("[\"~#'\",\"NL01\"]"), &id)).To(Succeed())
+		Expect(id).To(Equal(accountID("NL01")))
+	})
+
+	It("roundtrips single character tags", func() {
+		data, err := Marshal([]interface{}{accountID("NL01")})
+		Expect(err).To(BeNil())
+
+		var ids []accountID
+		Expect(Unmarshal(data, &ids)).To(Succeed())
+		Expect(ids).To(Equal([]accountID{"NL01"}))
+	})
+})
@@ -0,0 +1,141 @@
+package transit_go
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strconv"
+
+	"github.com/nedap/transit-go/constants"
+	"github.com/vmihailenco/msgpack"
+)
+
+type MsgpackEmitter struct {
+	out     io.Writer
+	writer  *bytes.Buffer
+	encoder *msgpack.Encoder
+	base    baseEmitter
+}
+
+func NewMsgpackEmitter(w io.Writer, writeHandlerMap WriteHandlerMap) Emitter {
+	return newMsgpackEmitter(w, newWriteHandlers(writeHandlerMap))
+}
+
+func newMsgpackEmitter(w io.Writer, handlers *writeHandlers) Emitter {
+	writer := new(bytes.Buffer)
+	msgpackEmitter := &MsgpackEmitter{out: w, writer: writer, encoder: msgpack.NewEncoder(writer)}
+	baseEmitter := baseEmitter{handlers: handlers, emitter: msgpackEmitter}
+	msgpackEmitter.base = baseEmitter
+	return msgpackEmitter
+}
+
+func (m *MsgpackEmitter) emit(obj interface{}, asMapKey bool, cache WriteCache) error {
+	return m.base.emitTop(obj, cache)
+}
+
+func (m *MsgpackEmitter) emitNil(asMapKey bool, cache WriteCache) error {
+	if asMapKey {
+		return m.emitString(constants.ESC_STR, "_", "", asMapKey, cache)
+	}
+	return m.encoder.EncodeNil()
+}
+
+func (m *MsgpackEmitter) emitString(prefix, tag, str string, asMapKey bool, cache WriteCache) error {
+	outString := cache.CacheWrite(maybePrefix(prefix, tag, str), asMapKey)
+	return m.encoder.EncodeString(outString)
+}
+
+func (m *MsgpackEmitter) emitBoolean(b bool, asMapKey bool, cache WriteCache) error {
+	if asMapKey {
+		var str string
+		if b {
+			str = "t"
+		} else {
+			str = "f"
+		}
+		return m.emitString(constants.ESC_STR, "?", str, asMapKey, cache)
+	}
+	return m.encoder.EncodeBool(b)
+}
+
+func (m *MsgpackEmitter) emitInteger(intValue int64, asMapKey bool, cache WriteCache) error {
+	if asMapKey {
+		return m.emitString(constants.ESC_STR, "i", strconv.FormatInt(intValue, 10), asMapKey, cache)
+	}
+	return m.encoder.EncodeInt(intValue)
+}
+
+func (m *MsgpackEmitter) emitDouble(floatValue float64, asMapKey bool, cache WriteCache) error {
+	if asMapKey {
+		return m.emitString(constants.ESC_STR, "d", strconv.FormatFloat(floatValue, 'f', -1, 64), asMapKey, cache)
+	}
+	return m.encoder.EncodeFloat64(floatValue)
+}
+
+func (m *MsgpackEmitter) emitBinary(bytes []byte, asMapKey bool, cache WriteCache) error {
+	if asMapKey {
+		return m.emitString(constants.ESC_STR, "b", base64.StdEncoding.EncodeToString(bytes), asMapKey, cache)
+	}
+	if bytes == nil {
+		// the encoder writes a nil slice as nil instead of as an empty bin
+		bytes = []byte{}
+	}
+	return m.encoder.EncodeBytes(bytes)
+}
+
+func (m *MsgpackEmitter) emitArrayStart(size int) error {
+	return m.encoder.EncodeArrayLen(size)
+}
+
+func (m *MsgpackEmitter) emitArrayEnd() error {
+	return nil
+}
+
+func (m *MsgpackEmitter) emitMapStart(size int) error {
+	return m.encoder.EncodeMapLen(size)
+}
+
+func (m *MsgpackEmitter) emitMapEnd() error {
+	return nil
+}
+
+func (m *MsgpackEmitter) emitTagged(t string, obj interface{}, ignored bool, cache WriteCache) error {
+	return m.base.emitTagged(t, obj, ignored, cache)
+}
+
+func (m *MsgpackEmitter) prefersStrings() bool {
+	return false
+}
+
+func (m *MsgpackEmitter) flushWriter() error {
+	_, err := m.writer.WriteTo(m.out)
+	m.writer.Reset()
+	return err
+}
+
+func (m *MsgpackEmitter) discardWriter() {
+	m.writer.Reset()
+}
+
+func (m *MsgpackEmitter) setCanonicalOrder(enabled bool) {
+	m.base.canonicalOrder = enabled
+}
+
+func (m *MsgpackEmitter) emitActualMap(entries mapEntries, ignored bool, cache WriteCache) error {
+	err := m.emitMapStart(len(entries))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = m.base.marshal(entry.key, true, cache)
+		if err != nil {
+			return err
+		}
+		err = m.base.marshal(entry.value, false, cache)
+		if err != nil {
+			return err
+		}
+	}
+	return m.emitMapEnd()
+}
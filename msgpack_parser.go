@@ -0,0 +1,247 @@
+package transit_go
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/nedap/transit-go/constants"
+	"github.com/vmihailenco/msgpack"
+	"github.com/vmihailenco/msgpack/codes"
+)
+
+type MsgpackParser struct {
+	decoder *msgpack.Decoder
+	base    baseParser
+}
+
+func NewMsgpackParser(decoder *msgpack.Decoder, handlers ReadHandlerMap, defaultHandler *DefaultReadHandler, mapBuilder MapReader, listBuilder ArrayReader) Parser {
+	msgpackParser := &MsgpackParser{decoder: decoder}
+
+	baseParser := baseParser{
+		readHandlerMap: handlers,
+		defaultHandler: defaultHandler,
+		mapBuilder:     mapBuilder,
+		arrayBuilder:   listBuilder,
+		parser:         msgpackParser,
+	}
+	msgpackParser.base = baseParser
+	return msgpackParser
+}
+
+func (p *MsgpackParser) parseString(str string) (interface{}, error) {
+	return p.base.parseString(str)
+}
+
+func (p *MsgpackParser) more() bool {
+	_, err := p.decoder.PeekCode()
+	return err == nil
+}
+
+func (p *MsgpackParser) atEnd() bool {
+	return !p.more()
+}
+
+func (p *MsgpackParser) parse(cache ReadCache) (interface{}, error) {
+	return p.parseVal(false, cache)
+}
+
+// maxPreallocatedItems is the largest capacity an array is created with
+const maxPreallocatedItems = 1024
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func isMsgpackMap(code codes.Code) bool {
+	return codes.IsFixedMap(code) || code == codes.Map16 || code == codes.Map32
+}
+
+func isMsgpackArray(code codes.Code) bool {
+	return codes.IsFixedArray(code) || code == codes.Array16 || code == codes.Array32
+}
+
+func (p *MsgpackParser) parseVal(asMapKey bool, cache ReadCache) (interface{}, error) {
+	code, err := p.decoder.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case isMsgpackMap(code):
+		return p.parseMap(asMapKey, cache, nil)
+	case isMsgpackArray(code):
+		return p.parseArray(asMapKey, cache, nil)
+	case codes.IsString(code):
+		str, err := p.decoder.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+		return cache.CacheRead(str, asMapKey, p)
+	case codes.IsBin(code):
+		return p.decoder.DecodeBytes()
+	case code == codes.Nil:
+		return nil, p.decoder.DecodeNil()
+	case code == codes.True || code == codes.False:
+		return p.decoder.DecodeBool()
+	case code == codes.Float || code == codes.Double:
+		return p.decoder.DecodeFloat64()
+	case code == codes.Uint64:
+		// an uint64 might not fit in an int, so preserve it as a big integer
+		u, err := p.decoder.DecodeUint64()
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return new(big.Int).SetUint64(u), nil
+		}
+		return int(u), nil
+	case codes.IsFixedNum(code), code == codes.Uint8, code == codes.Uint16, code == codes.Uint32,
+		code == codes.Int8, code == codes.Int16, code == codes.Int32, code == codes.Int64:
+		i, err := p.decoder.DecodeInt64()
+		if err != nil {
+			return nil, err
+		}
+		return int(i), nil
+	}
+	return nil, fmt.Errorf("Unsupported msgpack code %x", code)
+}
+
+func (p *MsgpackParser) parseMap(asMapKey bool, cache ReadCache, handler *MapReadHandler) (interface{}, error) {
+	size, err := p.decoder.DecodeMapLen()
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MapReader
+	if handler == nil {
+		mr = p.base.mapBuilder
+	} else {
+		mr = handler.mapReader
+	}
+
+	mb := mr.Init()
+
+	for i := 0; i < size; i++ {
+		key, err := p.parseVal(true, cache)
+		if err != nil {
+			return nil, err
+		}
+
+		if tag, ok := key.(Tag); ok {
+			if size != 1 {
+				return nil, misplacedTagError(tag)
+			}
+			return p.parseTagged(string(tag), cache)
+		}
+
+		val, err := p.parseVal(false, cache)
+		if err != nil {
+			return nil, err
+		}
+		mb = mr.Add(mb, key, val)
+	}
+
+	return mr.Complete(mb), nil
+}
+
+func (p *MsgpackParser) parseArray(ignored bool, cache ReadCache, handler *ArrayReadHandler) (interface{}, error) {
+	size, err := p.decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+
+	var arrayReader ArrayReader
+	if handler != nil {
+		arrayReader = handler.arrayReader
+	} else {
+		arrayReader = p.base.arrayBuilder
+	}
+
+	if size == 0 {
+		return arrayReader.Complete(arrayReader.Init(0)), nil
+	}
+
+	firstVal, err := p.parseVal(false, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	if firstVal == constants.MAP_AS_ARRAY {
+		if size%2 == 0 {
+			return nil, fmt.Errorf("Map as array of %d items has a key without a value", size)
+		}
+		mr := p.base.mapBuilder
+		mb := mr.Init()
+		for i := 1; i < size; i += 2 {
+			key, err := p.parseVal(true, cache)
+			if err != nil {
+				return nil, err
+			}
+			val, err := p.parseVal(false, cache)
+			if err != nil {
+				return nil, err
+			}
+			mb = mr.Add(mb, key, val)
+		}
+		return mr.Complete(mb), nil
+	} else if tag, isTag := firstVal.(Tag); isTag {
+		if size != 2 {
+			return nil, misplacedTagError(tag)
+		}
+		return p.parseTagged(string(tag), cache)
+	}
+
+	// the size is not trusted to allocate up front, malformed input may claim any size
+	ab := arrayReader.Init(minInt(size, maxPreallocatedItems))
+	ab = arrayReader.Add(ab, firstVal)
+	for i := 1; i < size; i++ {
+		val, err := p.parseVal(false, cache)
+		if err != nil {
+			return nil, err
+		}
+		ab = arrayReader.Add(ab, val)
+	}
+	return completeArray(arrayReader, ab)
+}
+
+// parseTagged reads the representation following a tag, and decodes it using
+// the handler registered for the tag
+func (p *MsgpackParser) parseTagged(tag string, cache ReadCache) (interface{}, error) {
+	valHandler, err := p.base.readHandlerMap.lookupHandler(tag)
+	if err != nil {
+		// default decode
+		parsedVal, err := p.parseVal(false, cache)
+		if err != nil {
+			return nil, err
+		}
+		return p.base.decode(tag, parsedVal)
+	}
+
+	code, err := p.decoder.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+
+	mapHandler, isMapHandler := valHandler.(MapReadHandler)
+	arrayHandler, isArrayHandler := valHandler.(ArrayReadHandler)
+
+	if isMsgpackMap(code) && isMapHandler {
+		return p.parseMap(false, cache, &mapHandler)
+	} else if isMsgpackArray(code) && isArrayHandler {
+		return p.parseArray(false, cache, &arrayHandler)
+	}
+
+	parsedVal, err := p.parseVal(false, cache)
+	if err != nil {
+		return nil, err
+	}
+	readHandler, ok := valHandler.(ReadHandler)
+	if !ok {
+		return nil, fmt.Errorf("Could not decode %s because the handler is not a ReadHandler", tag)
+	}
+	return readHandler.FromRep(parsedVal)
+}
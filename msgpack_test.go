@@ -0,0 +1,217 @@
+package transit_go
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/twinj/uuid"
+	"github.com/vmihailenco/msgpack"
+)
+
+var _ = Describe("Msgpack Writer", func() {
+	var writeMsgpack = func(obj interface{}) []byte {
+		var buffer bytes.Buffer
+		writer := NewMsgpackWriter(&buffer)
+		err := writer.Write(obj)
+		Expect(err).To(BeNil())
+		return writer.Buffer().Bytes()
+	}
+
+	var pack = func(obj interface{}) []byte {
+		var buffer bytes.Buffer
+		err := msgpack.NewEncoder(&buffer).UseCompactEncoding(true).Encode(obj)
+		Expect(err).To(BeNil())
+		return buffer.Bytes()
+	}
+
+	It("marshals nil", func() {
+		result := writeMsgpack(nil)
+		Expect(result).To(Equal(pack([]interface{}{"~#'", nil})))
+	})
+
+	It("marshals strings", func() {
+		result := writeMsgpack("a string")
+		Expect(result).To(Equal(pack([]interface{}{"~#'", "a string"})))
+	})
+
+	It("marshals integers natively", func() {
+		result := writeMsgpack(9007199254740999)
+		Expect(result).To(Equal(pack([]interface{}{"~#'", 9007199254740999})))
+	})
+
+	It("marshals negative integers natively", func() {
+		result := writeMsgpack(-24)
+		Expect(result).To(Equal(pack([]interface{}{"~#'", -24})))
+	})
+
+	It("marshals a float", func() {
+		result := writeMsgpack(3.14159265359)
+		Expect(result).To(Equal(pack([]interface{}{"~#'", 3.14159265359})))
+	})
+
+	It("marshals a byte slice as raw bin", func() {
+		result := writeMsgpack([]byte("hello world"))
+		Expect(result).To(Equal(pack([]interface{}{"~#'", []byte("hello world")})))
+	})
+
+	It("marshals a simple int array", func() {
+		result := writeMsgpack([]int{1, 2, 3, 4})
+		Expect(result).To(Equal(pack([]interface{}{1, 2, 3, 4})))
+	})
+
+	It("marshals maps as native maps", func() {
+		result := writeMsgpack(map[string]int{"key": 12})
+		Expect(result).To(Equal(pack(map[string]interface{}{"key": 12})))
+	})
+
+	It("marshals non-string keys as strings", func() {
+		result := writeMsgpack(map[int]bool{1: true})
+		Expect(result).To(Equal(pack(map[string]interface{}{"~i1": true})))
+	})
+
+	It("marshals and caches stringable keys", func() {
+		m := map[string]string{"name": "JW"}
+		result := writeMsgpack([]map[string]string{m, m})
+		Expect(result).To(Equal(pack([]interface{}{
+			map[string]interface{}{"name": "JW"},
+			map[string]interface{}{"^0": "JW"},
+		})))
+	})
+
+	It("marshals UUIDs as their big-endian halves", func() {
+		val, err := uuid.Parse("dda5a83f-8f9d-4194-ae88-5745c8ca94a7")
+		Expect(err).To(BeNil())
+		result := writeMsgpack(val)
+		Expect(result).To(Equal(pack([]interface{}{"~#'", []interface{}{"~#u", []interface{}{int64(-2475387429224365676), int64(-5870346157044362073)}}})))
+	})
+
+	It("marshals tagged values as arrays", func() {
+		result := writeMsgpack(TaggedValue{Tag: "point", Rep: []int{1, 2}})
+		Expect(result).To(Equal(pack([]interface{}{"~#point", []interface{}{1, 2}})))
+	})
+})
+
+var _ = Describe("Msgpack Reader", func() {
+	var writeMsgpack = func(obj interface{}) *bytes.Buffer {
+		var buffer bytes.Buffer
+		writer := NewMsgpackWriter(&buffer)
+		err := writer.Write(obj)
+		Expect(err).To(BeNil())
+		return &buffer
+	}
+
+	var readMsgpack = func(buffer *bytes.Buffer) interface{} {
+		reader := NewMsgpackReader(buffer)
+		result, err := reader.Read()
+		Expect(err).To(BeNil())
+		return result
+	}
+
+	var pack = func(obj interface{}) *bytes.Buffer {
+		var buffer bytes.Buffer
+		err := msgpack.NewEncoder(&buffer).Encode(obj)
+		Expect(err).To(BeNil())
+		return &buffer
+	}
+
+	It("roundtrips nil", func() {
+		Expect(readMsgpack(writeMsgpack(nil))).To(BeNil())
+	})
+
+	It("roundtrips strings", func() {
+		Expect(readMsgpack(writeMsgpack("hello"))).To(Equal("hello"))
+	})
+
+	It("roundtrips 64-bit integers exactly", func() {
+		val := 1<<62 + 1
+		Expect(readMsgpack(writeMsgpack(val))).To(Equal(val))
+		Expect(readMsgpack(writeMsgpack(-val))).To(Equal(-val))
+	})
+
+	It("reads unsigned 64-bit integers as big integers", func() {
+		result := readMsgpack(pack([]interface{}{"~#'", uint64(math.MaxUint64)}))
+		expected := new(big.Int).SetUint64(math.MaxUint64)
+		Expect(result).To(Equal(expected))
+	})
+
+	It("roundtrips ratios with big numerators and denominators", func() {
+		num, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		val := new(big.Rat).SetFrac(num, big.NewInt(7))
+		result := readMsgpack(writeMsgpack(val))
+		Expect(result.(*big.Rat).Cmp(val)).To(Equal(0))
+	})
+
+	It("roundtrips links", func() {
+		link := Link{Href: "/images/1.png", Rel: "icon", Prompt: "Icon", Render: "image"}
+		Expect(readMsgpack(writeMsgpack(link))).To(Equal(link))
+	})
+
+	It("roundtrips lists", func() {
+		Expect(readMsgpack(writeMsgpack(List{"a", List{1}}))).To(Equal(List{"a", List{1}}))
+	})
+
+	It("roundtrips floats", func() {
+		Expect(readMsgpack(writeMsgpack(3.14159265359))).To(Equal(3.14159265359))
+	})
+
+	It("reads bin as a byte slice", func() {
+		Expect(readMsgpack(writeMsgpack([]byte("hello world")))).To(Equal([]byte("hello world")))
+	})
+
+	It("roundtrips times", func() {
+		t := time.Unix(0, 1456231033010*int64(time.Millisecond)).UTC()
+		Expect(readMsgpack(writeMsgpack(t))).To(Equal(t))
+	})
+
+	It("roundtrips a simple int array", func() {
+		result := readMsgpack(writeMsgpack([]int{1, 2, 3, 4}))
+		Expect(result).To(Equal([]interface{}{1, 2, 3, 4}))
+	})
+
+	It("reads native maps as transit maps", func() {
+		result := readMsgpack(pack(map[string]interface{}{"key": 12})).(Map)
+		Expect(result.Equal(NewMap("key", 12))).To(BeTrue())
+	})
+
+	It("reads maps written as arrays", func() {
+		result := readMsgpack(pack([]interface{}{"^ ", "~i1", "hello"})).(Map)
+		Expect(result.Equal(NewMap(1, "hello"))).To(BeTrue())
+	})
+
+	It("reads maps with cached keys", func() {
+		m := map[string]string{"name": "JW"}
+		result := readMsgpack(writeMsgpack([]map[string]string{m, m, m})).([]interface{})
+		Expect(len(result)).To(Equal(3))
+		for _, v := range result {
+			Expect(v.(Map).Equal(NewMap("name", "JW"))).To(BeTrue())
+		}
+	})
+
+	It("reads sets", func() {
+		result := readMsgpack(pack([]interface{}{"~#set", []interface{}{"a"}}))
+		set, ok := result.(Set)
+		Expect(ok).To(BeTrue())
+		Expect(set.Items()).To(Equal([]interface{}{"a"}))
+	})
+
+	It("returns an error for a tag that is not the first of an array of two or the only key of a map", func() {
+		for _, obj := range []interface{}{
+			[]interface{}{"~#point", 1, 2},
+			[]interface{}{"~#point"},
+			map[string]interface{}{"~#point": 1, "a": 2},
+		} {
+			_, err := NewMsgpackReader(pack(obj)).Read()
+			Expect(err).To(MatchError("Tag point is not the first of an array of two or the only key of a map"), fmt.Sprint(obj))
+		}
+	})
+
+	It("turns unknown types into tagged values", func() {
+		result := readMsgpack(writeMsgpack(TaggedValue{Tag: "point", Rep: []int{1, 2}}))
+		Expect(result).To(Equal(TaggedValue{Tag: "point", Rep: []interface{}{1, 2}}))
+	})
+})
@@ -0,0 +1,112 @@
+package transit_go
+
+import "time"
+
+// Format is one of the encodings of transit.
+type Format int
+
+const (
+	FormatJSON Format = iota
+	FormatJSONVerbose
+	FormatMsgpack
+)
+
+type options struct {
+	format         Format
+	writeHandlers  WriteHandlerMap
+	readHandlers   ReadHandlerMap
+	caching        bool
+	canonicalOrder bool
+	location       *time.Location
+}
+
+// Option configures Marshal, Unmarshal, Encoders and Decoders.
+type Option func(*options)
+
+// WithFormat selects the encoding, which is FormatJSON by default. Decoding
+// FormatJSONVerbose is the same as decoding FormatJSON.
+func WithFormat(format Format) Option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// WithWriteHandlers adds custom handlers to the default write handlers.
+func WithWriteHandlers(handlers WriteHandlerMap) Option {
+	return func(o *options) {
+		for typ, handler := range handlers {
+			o.writeHandlers[typ] = handler
+		}
+	}
+}
+
+// WithReadHandlers adds custom handlers to the default read handlers.
+func WithReadHandlers(handlers ReadHandlerMap) Option {
+	return func(o *options) {
+		for tag, handler := range handlers {
+			o.readHandlers[tag] = handler
+		}
+	}
+}
+
+// WithCaching turns caching of map keys, keywords, symbols and tags on or off when
+// writing. It is on by default, except for FormatJSONVerbose which never caches.
+func WithCaching(enabled bool) Option {
+	return func(o *options) {
+		o.caching = enabled
+	}
+}
+
+// WithCanonicalOrder writes the entries of maps and the elements of sets ordered
+// by the encoding of their keys, instead of in the random order of Go maps. The
+// same value is then always written the same way, so the output can be hashed
+// or compared.
+func WithCanonicalOrder(enabled bool) Option {
+	return func(o *options) {
+		o.canonicalOrder = enabled
+	}
+}
+
+// WithUUIDAdapter writes and reads UUIDs of the type of the adapter, instead of
+// UUIDs of github.com/twinj/uuid.
+func WithUUIDAdapter(adapter UUIDAdapter) Option {
+	return func(o *options) {
+		o.writeHandlers[adapter.Type] = adapter.WriteHandler()
+		o.readHandlers["u"] = adapter.ReadHandler()
+	}
+}
+
+// WithLocation sets the location of the times that are read. By default times
+// are read in UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// decodeHandlers returns the read handlers to decode with: the default handlers,
+// with the time handlers in the configured location, and the custom handlers.
+func (o *options) decodeHandlers() ReadHandlerMap {
+	handlers := ReadHandlerMap{}
+	if o.location != nil {
+		handlers["t"] = verboseTimeReadHandler(o.location)
+		handlers["m"] = timeReadHandler(o.location)
+	}
+	for tag, handler := range o.readHandlers {
+		handlers[tag] = handler
+	}
+	return mergeReadHandlers(handlers)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		format:        FormatJSON,
+		writeHandlers: WriteHandlerMap{},
+		readHandlers:  ReadHandlerMap{},
+		caching:       true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -6,4 +6,8 @@ type Parser interface {
 	parseMap(asMapKey bool, cache ReadCache, handler *MapReadHandler) (interface{}, error)
 	parseArray(asMapKey bool, cache ReadCache, handler *ArrayReadHandler) (interface{}, error)
 	parseString(str string) (interface{}, error)
+	more() bool
+	// atEnd reports whether the input has ended, and has no data left after the
+	// values that were parsed
+	atEnd() bool
 }
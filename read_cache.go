@@ -1,15 +1,21 @@
 package transit_go
 
-import "github.com/nedap/transit-go/constants"
+import (
+	"fmt"
+
+	"github.com/nedap/transit-go/constants"
+)
 
 type ReadCache interface {
-	CacheRead(str string, asMapKey bool, parser Parser) interface{}
+	CacheRead(str string, asMapKey bool, parser Parser) (interface{}, error)
 	Init()
 }
 
+// readCache holds the values of the cacheable strings that were read, at the
+// index of their cache code. Like the write cache, it starts over after
+// MaxCacheEntries values, so the next value gets cache code ^0 again.
 type readCache struct {
 	cache []interface{}
-	index int
 }
 
 func NewReadCache() ReadCache {
@@ -19,39 +25,37 @@ func NewReadCache() ReadCache {
 }
 
 func (c *readCache) Init() {
-	c.index = 0
+	c.cache = c.cache[:0]
 }
 
-func (c *readCache) CacheRead(str string, asMapKey bool, parser Parser) interface{} {
+func (c *readCache) CacheRead(str string, asMapKey bool, parser Parser) (interface{}, error) {
 	if len(str) != 0 {
 		if cacheCode(str) {
-			realVal := c.cache[codeToIndex(str)]
-			return realVal
+			index := codeToIndex(str)
+			if index < 0 || index >= len(c.cache) {
+				return nil, fmt.Errorf("Unknown cache code %s", str)
+			}
+			return c.cache[index], nil
 		} else if isCacheable(str, asMapKey) {
-			if c.index == constants.MaxCacheEntries {
+			if len(c.cache) == constants.MaxCacheEntries {
 				c.Init()
 			}
-			var value interface{}
-
-			if parser != nil {
-				parseResult, _ := parser.parseString(str)
-				tag, isTag := parseResult.(Tag)
-				if isTag {
-					value = tag
-				} else {
-					value = parseResult
-				}
+			value, err := parseCacheString(str, parser)
+			if err != nil {
+				return nil, err
 			}
 			c.cache = append(c.cache, value)
-			c.index++
+			return value, nil
 		}
 	}
-	if parser != nil {
-		parseResult, _ := parser.parseString(str)
-		return parseResult
-	} else {
-		return str
+	return parseCacheString(str, parser)
+}
+
+func parseCacheString(str string, parser Parser) (interface{}, error) {
+	if parser == nil {
+		return str, nil
 	}
+	return parser.parseString(str)
 }
 
 func cacheCode(str string) bool {
@@ -63,6 +67,8 @@ func codeToIndex(code string) int {
 	if length == 2 {
 		val := int(code[1] - constants.BaseCharIndex)
 		return val
+	} else if length != 3 {
+		return -1
 	} else {
 		val := (int(code[1]-constants.BaseCharIndex) * constants.CacheCodeDigits) +
 			(int(code[2] - constants.BaseCharIndex))
@@ -8,21 +8,27 @@ import (
 	"net/url"
 	"strconv"
 	"time"
-
-	"github.com/twinj/uuid"
+	"unicode/utf8"
 )
 
+// stringRep returns the representation of a scalar tag, which has to be a string
+func stringRep(rep interface{}, typeName string) (string, error) {
+	strRep, ok := rep.(string)
+	if !ok {
+		return "", fmt.Errorf("Could not convert %v to %s, expected a string", rep, typeName)
+	}
+	return strRep, nil
+}
+
 func bigDecimalReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Big Decimal",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
-			var bigFloat big.Float
-			fl, _, err := bigFloat.Parse(strRep, 10)
+			strRep, err := stringRep(rep, "BigDecimal")
 			if err != nil {
 				return nil, err
 			}
-			return fl, nil
+			return ParseBigDecimal(strRep)
 		},
 	}
 }
@@ -31,9 +37,11 @@ func bigIntegerReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Big Integer",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
-			var bigInt *big.Int
-			bigInt, ok := bigInt.SetString(strRep, 10)
+			strRep, err := stringRep(rep, "big.Int")
+			if err != nil {
+				return nil, err
+			}
+			bigInt, ok := new(big.Int).SetString(strRep, 10)
 			if !ok {
 				return nil, fmt.Errorf("Could not convert '%s' to big.Int", strRep)
 			}
@@ -46,7 +54,10 @@ func binaryReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Binary",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
+			strRep, err := stringRep(rep, "binary")
+			if err != nil {
+				return nil, err
+			}
 			bytes, err := base64.StdEncoding.DecodeString(strRep)
 			if err != nil {
 				return nil, err
@@ -60,7 +71,10 @@ func booleanReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Boolean",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
+			strRep, err := stringRep(rep, "a boolean")
+			if err != nil {
+				return nil, err
+			}
 			return strRep == "t", nil
 		},
 	}
@@ -70,45 +84,64 @@ func characterReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Character",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
-			return rune(strRep[0]), nil
+			strRep, err := stringRep(rep, "a character")
+			if err != nil {
+				return nil, err
+			}
+			r, size := utf8.DecodeRuneInString(strRep)
+			if size == 0 {
+				return nil, fmt.Errorf("Could not convert '%s' to a character", strRep)
+			}
+			return r, nil
 		},
 	}
 }
 
 /* cMapReadHandler */
+
+// MapKey wrapped the keys of maps that were read as map[*MapKey]interface{}.
+//
+// Deprecated: maps are read as a Map, and nothing in this package creates a
+// MapKey any more. The type is only kept so existing code keeps compiling.
 type MapKey struct {
 	Key interface{}
 }
 
-func newMapKey(key interface{}) *MapKey {
-	return &MapKey{Key: key}
-}
+// cMapArrayReader reads the alternating keys and values of a cmap. It keeps no
+// state itself, so one reader can be used for nested cmaps and by concurrent reads.
+type cMapArrayReader struct{}
 
-type cMapArrayReader struct {
-	m       map[interface{}]interface{}
-	nextKey interface{}
+// cMapBuilder is the map being read, with the key that waits for its value
+type cMapBuilder struct {
+	m          *Map
+	nextKey    interface{}
+	hasNextKey bool
 }
 
-func (c *cMapArrayReader) Init(size int) interface{} {
-	m := make(map[*MapKey]interface{})
-	return m
+func (c cMapArrayReader) Init(size int) interface{} {
+	return &cMapBuilder{m: &Map{}}
 }
 
-func (c *cMapArrayReader) Add(a interface{}, item interface{}) interface{} {
-	m, _ := a.(map[*MapKey]interface{})
-	if c.nextKey != nil {
-		mk := newMapKey(c.nextKey)
-		m[mk] = item
-		c.nextKey = nil
+func (c cMapArrayReader) Add(a interface{}, item interface{}) interface{} {
+	b, _ := a.(*cMapBuilder)
+	if b.hasNextKey {
+		b.m.put(b.nextKey, item)
+		b.nextKey, b.hasNextKey = nil, false
 	} else {
-		c.nextKey = item
+		b.nextKey, b.hasNextKey = item, true
 	}
-	return m
+	return b
 }
 
-func (c *cMapArrayReader) Complete(a interface{}) interface{} {
-	return a
+func (c cMapArrayReader) Complete(a interface{}) interface{} {
+	return *(a.(*cMapBuilder).m)
+}
+
+func (c cMapArrayReader) check(a interface{}) error {
+	if b := a.(*cMapBuilder); b.hasNextKey {
+		return fmt.Errorf("cmap key %v has no value", b.nextKey)
+	}
+	return nil
 }
 
 func cmapReadHandler() ArrayReadHandler {
@@ -118,14 +151,17 @@ func cmapReadHandler() ArrayReadHandler {
 			return nil, fmt.Errorf("'FromRep' is not supported")
 		},
 	}
-	return ArrayReadHandler{ReadHandler: rh, arrayReader: new(cMapArrayReader)}
+	return ArrayReadHandler{ReadHandler: rh, arrayReader: cMapArrayReader{}}
 }
 
 func doubleReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Double",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
+			strRep, err := stringRep(rep, "a double")
+			if err != nil {
+				return nil, err
+			}
 			return strconv.ParseFloat(strRep, 64)
 		},
 	}
@@ -135,7 +171,10 @@ func specialNumberReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Special Number",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
+			strRep, err := stringRep(rep, "a special number")
+			if err != nil {
+				return nil, err
+			}
 			if strRep == "NaN" {
 				return math.NaN(), nil
 			} else if strRep == "INF" {
@@ -162,12 +201,19 @@ func integerReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Integer",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
-			bigInt, err := strconv.ParseInt(strRep, 10, 64)
+			strRep, err := stringRep(rep, "an integer")
 			if err != nil {
 				return nil, err
 			}
-			return int(bigInt), nil
+			i, err := strconv.ParseInt(strRep, 10, 64)
+			if err != nil {
+				// an integer that does not fit in an int64 is read as big integer
+				if bigInt, ok := new(big.Int).SetString(strRep, 10); ok {
+					return bigInt, nil
+				}
+				return nil, err
+			}
+			return int(i), nil
 		},
 	}
 }
@@ -176,7 +222,10 @@ func keywordReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Keyword",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
+			strRep, err := stringRep(rep, "a keyword")
+			if err != nil {
+				return nil, err
+			}
 			return Keyword(strRep), nil
 		},
 	}
@@ -186,12 +235,12 @@ func keywordReadHandler() ReadHandler {
 type listArrayReader struct{}
 
 func (c listArrayReader) Init(size int) interface{} {
-	list := make([]interface{}, size)
+	list := make(List, 0, size)
 	return list
 }
 
 func (c listArrayReader) Add(a interface{}, item interface{}) interface{} {
-	list, _ := a.([]interface{})
+	list, _ := a.(List)
 	list = append(list, item)
 	return list
 }
@@ -219,13 +268,32 @@ func nullReadHandler() ReadHandler {
 	}
 }
 
+// ratioPart returns the numerator or denominator of a ratio, which are written as
+// big integers but may be read as integers as well
+func ratioPart(obj interface{}) (*big.Int, bool) {
+	switch i := obj.(type) {
+	case *big.Int:
+		return i, true
+	case int:
+		return big.NewInt(int64(i)), true
+	}
+	return nil, false
+}
+
 func ratioReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Ratio",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			list, _ := rep.([]int64)
-			rational := big.NewRat(list[0], list[1])
-			return rational, nil
+			list, ok := rep.([]interface{})
+			if !ok || len(list) != 2 {
+				return nil, fmt.Errorf("Could not convert %v to big.Rat, expected a numerator and denominator", rep)
+			}
+			num, numOk := ratioPart(list[0])
+			denom, denomOk := ratioPart(list[1])
+			if !numOk || !denomOk || denom.Sign() == 0 {
+				return nil, fmt.Errorf("Could not convert %v to big.Rat", rep)
+			}
+			return new(big.Rat).SetFrac(num, denom), nil
 		},
 	}
 }
@@ -262,46 +330,72 @@ func symbolReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Symbol",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
+			strRep, err := stringRep(rep, "a symbol")
+			if err != nil {
+				return nil, err
+			}
 			return Symbol(strRep), nil
 		},
 	}
 }
 
-func timeReadHandler() ReadHandler {
+// timeReadHandler reads ~m times, in milliseconds since the epoch, in the location loc
+func timeReadHandler(loc *time.Location) ReadHandler {
 	return ReadHandler{
 		Name: "Time",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			intRep, ok := rep.(int64)
-			if !ok {
-				strRep, _ := rep.(string)
-				intRep, _ = strconv.ParseInt(strRep, 10, 64)
+			var millis int64
+			switch r := rep.(type) {
+			case int64:
+				millis = r
+			case int:
+				millis = int64(r)
+			case string:
+				var err error
+				millis, err = strconv.ParseInt(r, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Could not convert '%s' to a time", r)
+				}
+			default:
+				return nil, fmt.Errorf("Could not convert %v to a time", rep)
 			}
 
-			millis := intRep
-			secs := millis / 1000
-			rest := millis % 1000
-			return time.Unix(secs, rest*int64(time.Millisecond)), nil
+			secs, ms := millis/1000, millis%1000
+			if ms < 0 {
+				secs, ms = secs-1, ms+1000
+			}
+			return time.Unix(secs, ms*int64(time.Millisecond)).In(loc), nil
 		},
 	}
 }
 
-func uriReadHandler() ReadHandler {
+// verboseTimeReadHandler reads ~t times, which are RFC 3339 timestamps, in the location loc
+func verboseTimeReadHandler(loc *time.Location) ReadHandler {
 	return ReadHandler{
-		Name: "URI",
+		Name: "Verbose Time",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
-			return url.Parse(strRep)
+			strRep, ok := rep.(string)
+			if !ok {
+				return nil, fmt.Errorf("Could not convert %v to a time", rep)
+			}
+			t, err := time.Parse(time.RFC3339Nano, strRep)
+			if err != nil {
+				return nil, fmt.Errorf("Could not convert '%s' to a time", strRep)
+			}
+			return t.In(loc), nil
 		},
 	}
 }
 
-func uuidReadHandler() ReadHandler {
+func uriReadHandler() ReadHandler {
 	return ReadHandler{
-		Name: "UUID",
+		Name: "URI",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			strRep, _ := rep.(string)
-			return uuid.Parse(strRep)
+			strRep, err := stringRep(rep, "a URI")
+			if err != nil {
+				return nil, err
+			}
+			return url.Parse(strRep)
 		},
 	}
 }
@@ -310,9 +404,26 @@ func linkReadHandler() ReadHandler {
 	return ReadHandler{
 		Name: "Link",
 		FromRep: func(rep interface{}) (interface{}, error) {
-			linkMap, ok := rep.(map[string]string)
+			m, ok := rep.(Map)
 			if !ok {
-				return nil, fmt.Errorf("Could not convert representation to map[string]string")
+				return nil, fmt.Errorf("Could not convert representation to a link, expected a map")
+			}
+			linkMap := make(map[string]string)
+			var err error
+			m.Range(func(key, value interface{}) bool {
+				name, _ := key.(string)
+				switch v := value.(type) {
+				case string:
+					linkMap[name] = v
+				case *url.URL:
+					linkMap[name] = v.String()
+				default:
+					err = fmt.Errorf("Could not convert link %s %v to a string", name, value)
+				}
+				return err == nil
+			})
+			if err != nil {
+				return nil, err
 			}
 			return NewLinkFromMap(linkMap)
 		},
@@ -2,6 +2,8 @@ package transit_go
 
 import (
 	"bytes"
+	"math"
+	"math/big"
 	"net/url"
 	"time"
 
@@ -23,7 +25,8 @@ var _ = Describe("Write & Read", func() {
 	var read = func(str string) interface{} {
 		buffer := bytes.NewBufferString(str)
 		reader := NewJSONReader(buffer)
-		result := reader.Read()
+		result, err := reader.Read()
+		Expect(err).To(BeNil())
 		return result
 	}
 
@@ -34,7 +37,8 @@ var _ = Describe("Write & Read", func() {
 		err := writer.Write(obj)
 		Expect(err).To(BeNil())
 		reader := NewJSONReader(&buffer)
-		result := reader.Read()
+		result, err := reader.Read()
+		Expect(err).To(BeNil())
 
 		Expect(result).To(Equal(obj))
 	})
@@ -64,6 +68,65 @@ var _ = Describe("Write & Read", func() {
 		Expect(result).To(Equal(val))
 	})
 
+	It("roundtrips 64-bit and arbitrary precision integers", func() {
+		n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		result := read(write([]interface{}{int64(math.MaxInt64), uint64(math.MaxUint64), n}))
+
+		values := result.([]interface{})
+		Expect(values[0]).To(Equal(math.MaxInt64))
+		Expect(values[1].(*big.Int).String()).To(Equal("18446744073709551615"))
+		Expect(values[2].(*big.Int).Cmp(n)).To(Equal(0))
+	})
+
+	It("reads integers beyond the int64 range as big integers", func() {
+		result := read("[\"~i18446744073709551616\",18446744073709551617]")
+		values := result.([]interface{})
+		Expect(values[0].(*big.Int).String()).To(Equal("18446744073709551616"))
+		Expect(values[1].(*big.Int).String()).To(Equal("18446744073709551617"))
+	})
+
+	It("roundtrips ratios with arbitrarily large parts", func() {
+		num, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+		denom, _ := new(big.Int).SetString("98765432109876543210987654321", 10)
+		val := new(big.Rat).SetFrac(num, denom)
+
+		result := read(write(val))
+		Expect(result.(*big.Rat).Cmp(val)).To(Equal(0))
+	})
+
+	It("roundtrips lists as lists", func() {
+		str := "[\"~#list\",[1,\"~:a\",[\"^0\",[]]]]"
+		result := read(str)
+		Expect(result).To(Equal(List{1, Keyword("a"), List{}}))
+		Expect(write(result)).To(Equal(str))
+	})
+
+	It("roundtrips links", func() {
+		link, err := NewLink("http://example.com/people/1", "person", "Alice", "", "link")
+		Expect(err).To(BeNil())
+
+		str := write(link)
+		Expect(str).To(Equal("[\"~#link\",[\"^ \",\"href\",\"~rhttp://example.com/people/1\",\"rel\",\"person\",\"name\",\"Alice\",\"render\",\"link\"]]"))
+		Expect(read(str)).To(Equal(link))
+	})
+
+	It("reads links with only href and rel", func() {
+		result := read("[\"~#link\",[\"^ \",\"href\",\"~r/orders\",\"rel\",\"orders\"]]")
+		Expect(result).To(Equal(Link{Href: "/orders", Rel: "orders"}))
+	})
+
+	It("reads ratios written with plain integers", func() {
+		result := read("[\"~#ratio\",[1,3]]")
+		Expect(result.(*big.Rat).Cmp(big.NewRat(1, 3))).To(Equal(0))
+	})
+
+	It("returns an error for invalid ratios", func() {
+		for _, str := range []string{"[\"~#ratio\",[1]]", "[\"~#ratio\",[\"~n1\",\"~n0\"]]", "[\"~#ratio\",\"1/3\"]"} {
+			_, err := NewJSONReader(bytes.NewBufferString(str)).Read()
+			Expect(err).NotTo(BeNil(), str)
+		}
+	})
+
 	It("roundtrips floats", func() {
 		val := 3.14159265359
 		result := read(write(val))
@@ -78,11 +141,21 @@ var _ = Describe("Write & Read", func() {
 
 	It("roundtrips times", func() {
 		timeInMillis := 1456231033010
-		t := time.Unix(0, int64(timeInMillis)*int64(time.Millisecond))
+		t := time.Unix(0, int64(timeInMillis)*int64(time.Millisecond)).UTC()
 		result := read(write(t))
 		Expect(result).To(Equal(t))
 	})
 
+	It("roundtrips times outside the range of nanoseconds since 1970", func() {
+		for _, t := range []time.Time{
+			{},
+			time.Date(2300, 1, 1, 12, 30, 0, 5000000, time.UTC),
+			time.Date(1969, 12, 31, 23, 59, 58, 500000000, time.UTC),
+		} {
+			Expect(read(write(t))).To(Equal(t))
+		}
+	})
+
 	It("roundtrips runes", func() {
 		val := 'a'
 		result := read(write(val))
@@ -115,23 +188,14 @@ var _ = Describe("Write & Read", func() {
 
 	It("roundtrips simple map", func() {
 		val := map[string]int{"key": 12}
-		result := (read(write(val))).(map[*MapKey]interface{})
-
-		for mapKey, v := range result {
-			actualKey := mapKey.Key.(string)
-
-			Expect(v).To(Equal(val[actualKey]))
-		}
+		result := (read(write(val))).(Map)
+		Expect(result.Equal(NewMap("key", 12))).To(BeTrue())
 	})
 
 	It("roundtrips a non-stringable key map", func() {
 		val := map[int]string{1: "hello", 2: "world"}
-		result := (read(write(val))).(map[*MapKey]interface{})
-
-		for mapKey, v := range result {
-			actualKey := mapKey.Key.(int)
-			Expect(v).To(Equal(val[actualKey]))
-		}
+		result := (read(write(val))).(Map)
+		Expect(result.Equal(NewMap(1, "hello", 2, "world"))).To(BeTrue())
 	})
 
 	It("roundtrips a map with an intslice as key and byte slices as values", func() {
@@ -141,15 +205,25 @@ var _ = Describe("Write & Read", func() {
 			[3]int{13, 14, 15}: []byte("world"),
 		}
 
-		result := (read(write(val))).(map[*MapKey]interface{})
-		for k, v := range result {
-			actualKey := (*k).Key.([]interface{})
-			Expect(len(actualKey)).To(Equal(3))
-			testArr := [3]int{actualKey[0].(int), actualKey[1].(int), actualKey[2].(int)}
-			Expect(val[testArr]).To(Equal(v))
+		result := (read(write(val))).(Map)
+		Expect(result.Len()).To(Equal(3))
+		for k, v := range val {
+			actual, ok := result.Get(k)
+			Expect(ok).To(BeTrue())
+			Expect(actual).To(Equal(v))
 		}
 	})
 
+	It("writes back maps it reads", func() {
+		str := "[\"^ \",\"a\",[\"^ \",\"b\",[1,2]]]"
+		Expect(write(read(str))).To(Equal(str))
+	})
+
+	It("writes back keywords and symbols it reads", func() {
+		str := "[\"^ \",\"~:ns/key\",[\"~$sym\",\"~:value\"]]"
+		Expect(write(read(str))).To(Equal(str))
+	})
+
 	It("reads", func() {
 		var performExamplarRoundTrip = func() {
 			write(read(examplar()))
@@ -1,13 +1,16 @@
 package transit_go
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"time"
+
+	"github.com/vmihailenco/msgpack"
 )
 
 type TransmitReader interface {
-	Read() interface{}
+	Read() (interface{}, error)
 }
 
 type ReadHandlerMap map[string]interface{}
@@ -21,6 +24,10 @@ type JSONReader struct {
 	transmitReader
 }
 
+type MsgpackReader struct {
+	transmitReader
+}
+
 func defaultReadHandlers() ReadHandlerMap {
 	handlers := ReadHandlerMap{
 		":":     keywordReadHandler(),
@@ -33,10 +40,10 @@ func defaultReadHandlers() ReadHandlerMap {
 		"d":     doubleReadHandler(),
 		"z":     specialNumberReadHandler(),
 		"c":     characterReadHandler(),
-		"t":     timeReadHandler(),
-		"m":     timeReadHandler(),
+		"t":     verboseTimeReadHandler(time.UTC),
+		"m":     timeReadHandler(time.UTC),
 		"r":     uriReadHandler(),
-		"u":     uuidReadHandler(),
+		"u":     TwinjUUIDAdapter().ReadHandler(),
 		"b":     binaryReadHandler(),
 		"'":     identityReadHandler(),
 		"set":   setReadHandler(),
@@ -48,29 +55,58 @@ func defaultReadHandlers() ReadHandlerMap {
 	return handlers
 }
 
-func NewJSONReader(buffer *bytes.Buffer) JSONReader {
-	return NewJSONReaderWithHandlers(buffer, ReadHandlerMap{})
+func NewJSONReader(r io.Reader) JSONReader {
+	return NewJSONReaderWithHandlers(r, ReadHandlerMap{})
 }
 
-func NewJSONReaderWithHandlers(buffer *bytes.Buffer, customHandlers ReadHandlerMap) JSONReader {
-	handlers := defaultReadHandlers()
+func NewJSONReaderWithHandlers(r io.Reader, customHandlers ReadHandlerMap) JSONReader {
+	handlers := mergeReadHandlers(customHandlers)
 
-	for tag, handler := range customHandlers {
-		handlers[tag] = handler
+	reader := JSONReader{
+		transmitReader{
+			handlers: handlers,
+			parser:   newJsonReaderParser(r, handlers),
+		},
 	}
-	jsonDecoder := json.NewDecoder(buffer)
-	jsonDecoder.UseNumber()
+	return reader
+}
 
-	parser := NewJsonParser(jsonDecoder, handlers, defaultReadHandler(), defaultMapBuilder(), defaultListBuilder())
-	reader := JSONReader{
+func NewMsgpackReader(r io.Reader) MsgpackReader {
+	return NewMsgpackReaderWithHandlers(r, ReadHandlerMap{})
+}
+
+func NewMsgpackReaderWithHandlers(r io.Reader, customHandlers ReadHandlerMap) MsgpackReader {
+	handlers := mergeReadHandlers(customHandlers)
+
+	reader := MsgpackReader{
 		transmitReader{
 			handlers: handlers,
-			parser:   parser,
+			parser:   newMsgpackReaderParser(r, handlers),
 		},
 	}
 	return reader
 }
 
+func newJsonReaderParser(r io.Reader, handlers ReadHandlerMap) Parser {
+	jsonDecoder := json.NewDecoder(r)
+	jsonDecoder.UseNumber()
+
+	return NewJsonParser(jsonDecoder, handlers, defaultReadHandler(), defaultMapBuilder(), defaultListBuilder())
+}
+
+func newMsgpackReaderParser(r io.Reader, handlers ReadHandlerMap) Parser {
+	return NewMsgpackParser(msgpack.NewDecoder(r), handlers, defaultReadHandler(), defaultMapBuilder(), defaultListBuilder())
+}
+
+func mergeReadHandlers(customHandlers ReadHandlerMap) ReadHandlerMap {
+	handlers := defaultReadHandlers()
+
+	for tag, handler := range customHandlers {
+		handlers[tag] = handler
+	}
+	return handlers
+}
+
 func defaultReadHandler() *DefaultReadHandler {
 	return &DefaultReadHandler{
 		FromRep: func(tag string, rep interface{}) (interface{}, error) {
@@ -95,10 +131,10 @@ func (m ReadHandlerMap) lookupHandler(tag string) (interface{}, error) {
 	return handler, nil
 }
 
-func (r JSONReader) Read() interface{} {
-	val, err := r.parser.parse(NewReadCache())
-	if err != nil {
-		panic(err)
-	}
-	return val
+func (r JSONReader) Read() (interface{}, error) {
+	return r.parser.parse(NewReadCache())
+}
+
+func (r MsgpackReader) Read() (interface{}, error) {
+	return r.parser.parse(NewReadCache())
 }
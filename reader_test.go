@@ -3,6 +3,7 @@ package transit_go
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/url"
 	"time"
 
@@ -15,7 +16,8 @@ var _ = Describe("JSON Reader", func() {
 	var readString = func(str string) interface{} {
 		buffer := bytes.NewBufferString(str)
 		reader := NewJSONReader(buffer)
-		result := reader.Read()
+		result, err := reader.Read()
+		Expect(err).To(BeNil())
 		return result
 	}
 
@@ -56,7 +58,7 @@ var _ = Describe("JSON Reader", func() {
 
 	It("reads times", func() {
 		timeInMillis := 1456231033010
-		t := time.Unix(0, int64(timeInMillis)*int64(time.Millisecond))
+		t := time.Unix(0, int64(timeInMillis)*int64(time.Millisecond)).UTC()
 
 		result := readString(fmt.Sprintf("[\"~#'\",\"~m%d\"]", timeInMillis))
 		Expect(result).To(Equal(t))
@@ -83,64 +85,87 @@ var _ = Describe("JSON Reader", func() {
 		Expect(result).To(Equal([]interface{}{1, 2, 3, 4}))
 	})
 
-	It("reads a simple map", func() {
-		result := readString("[\"^ \",\"key\",12]").(map[*MapKey]interface{})
-		expected := map[string]int{"key": 12}
+	It("reads an empty array", func() {
+		result := readString("[]")
+		Expect(result).To(Equal([]interface{}{}))
+	})
 
-		for mapKey, v := range result {
-			actualKey := mapKey.Key.(string)
-			Expect(v).To(Equal(expected[actualKey]))
-		}
+	It("reads a simple map", func() {
+		result := readString("[\"^ \",\"key\",12]").(Map)
+		Expect(result.Equal(NewMap("key", 12))).To(BeTrue())
 	})
 
 	It("reads a non-stringable simple map", func() {
 		r := readString("[\"^ \",\"~i1\",\"hello\", \"~i2\", \"world\"]")
-		result := r.(map[*MapKey]interface{})
-		expected := map[int]string{1: "hello", 2: "world"}
-
-		for mapKey, v := range result {
-			actualKey := mapKey.Key.(int)
-			Expect(v).To(Equal(expected[actualKey]))
-		}
+		result := r.(Map)
+		Expect(result.Equal(NewMap(1, "hello", 2, "world"))).To(BeTrue())
 	})
 
 	It("reads a simple map with cached keys", func() {
 		result := readString("[[\"^ \",\"name\",\"JW\",\"town\",\"Enschede\"],[\"^ \",\"^0\",\"JW\",\"^1\",\"Enschede\"],[\"^ \",\"^0\",\"JW\",\"^1\",\"Enschede\"]]")
 
-		m := map[interface{}]interface{}{"name": "JW", "town": "Enschede"}
+		m := NewMap("name", "JW", "town", "Enschede")
 		resultSlice, ok := result.([]interface{})
 		Expect(ok)
 		Expect(len(resultSlice)).To(Equal(3))
 		for _, v := range resultSlice {
-			subResult := v.(map[*MapKey]interface{})
-
-			for mapKey, val := range subResult {
-				realKey := mapKey.Key.(string)
-				Expect(val).To(Equal(m[realKey]))
-			}
+			Expect(v.(Map).Equal(m)).To(BeTrue())
 		}
 	})
 
 	It("reads a complex map", func() {
 		result := readString("[\"~#cmap\",[[1,2,3],\"~bZ29vZGJ5ZQ==\",[7,8,9],\"~bY3J1ZWw=\",[13,14,15],\"~bd29ybGQ=\"]]")
 
-		resultMap, ok := result.(map[*MapKey]interface{})
-		Expect(ok)
-		Expect(len(resultMap)).To(Equal(3))
-
-		for k, v := range resultMap {
-			realKey := k.Key.([]interface{})
-			firstElem := realKey[0]
-			firstInt, ok := firstElem.(int)
-			Expect(ok)
-			switch firstInt {
-			case 1:
-				Expect(v).To(Equal([]byte("goodbye")))
-			case 7:
-				Expect(v).To(Equal([]byte("cruel")))
-			case 13:
-				Expect(v).To(Equal([]byte("world")))
-			}
+		resultMap, ok := result.(Map)
+		Expect(ok).To(BeTrue())
+		Expect(resultMap.Len()).To(Equal(3))
+
+		v, ok := resultMap.Get([]interface{}{1, 2, 3})
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal([]byte("goodbye")))
+		v, _ = resultMap.Get([]int{7, 8, 9})
+		Expect(v).To(Equal([]byte("cruel")))
+		v, _ = resultMap.Get([]interface{}{13, 14, 15})
+		Expect(v).To(Equal([]byte("world")))
+	})
+
+	It("reads nested complex maps and nil keys", func() {
+		result := readString("[\"~#cmap\",[[\"^0\",[[1],\"inner\"]],\"outer\",null,\"nil\"]]").(Map)
+		Expect(result.Len()).To(Equal(2))
+
+		v, ok := result.Get(NewMap([]int{1}, "inner"))
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal("outer"))
+		v, ok = result.Get(nil)
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal("nil"))
+	})
+
+	It("returns an error for a complex map key without a value", func() {
+		_, err := NewJSONReader(bytes.NewBufferString("[\"~#cmap\",[[1],2,[3]]]")).Read()
+		Expect(err).To(MatchError("cmap key [3] has no value"))
+
+		data := append([]byte{0x92, 0xa6}, "~#cmap"...)
+		data = append(data, 0x93, 0x91, 0x01, 0x02, 0x91, 0x03)
+		_, err = NewMsgpackReader(bytes.NewBuffer(data)).Read()
+		Expect(err).To(MatchError("cmap key [3] has no value"))
+	})
+
+	It("reads complex maps concurrently", func() {
+		str := "[\"~#cmap\",[[1],\"a\",[2],\"b\",[3],\"c\"]]"
+		expected := NewMap([]int{1}, "a", []int{2}, "b", []int{3}, "c")
+
+		results := make(chan Map)
+		for i := 0; i < 20; i++ {
+			go func() {
+				defer GinkgoRecover()
+				result, err := NewJSONReader(bytes.NewBufferString(str)).Read()
+				Expect(err).To(BeNil())
+				results <- result.(Map)
+			}()
+		}
+		for i := 0; i < 20; i++ {
+			Expect((<-results).Equal(expected)).To(BeTrue())
 		}
 	})
 
@@ -150,19 +175,9 @@ var _ = Describe("JSON Reader", func() {
 		Expect(ok)
 
 		Expect(tv.Tag).To(Equal("point"))
-		valMap, ok := tv.Rep.(map[*MapKey]interface{})
-		Expect(ok)
-		Expect(len(valMap)).To(Equal(2))
-
-		expected := map[string]float64{
-			"x": 3.140000104904175,
-			"y": 100.0,
-		}
-
-		for k, v := range valMap {
-			realKey := k.Key.(string)
-			Expect(v).To(Equal(expected[realKey]))
-		}
+		valMap, ok := tv.Rep.(Map)
+		Expect(ok).To(BeTrue())
+		Expect(valMap.Equal(NewMap("x", 3.140000104904175, "y", 100.0))).To(BeTrue())
 	})
 
 	It("allows custom readers", func() {
@@ -173,14 +188,15 @@ var _ = Describe("JSON Reader", func() {
 
 		pointReader := ReadHandler{
 			FromRep: func(rep interface{}) (interface{}, error) {
-				repAsMap, ok := rep.(map[*MapKey]interface{})
+				repAsMap, ok := rep.(Map)
 				if !ok {
-					return nil, fmt.Errorf("Expected to be able to type assert to map[*MapKey]interface{}")
+					return nil, fmt.Errorf("Expected to be able to type assert to Map")
 				}
 				pointMap := make(map[string]interface{})
-				for mapKey, v := range repAsMap {
-					pointMap[mapKey.Key.(string)] = v
-				}
+				repAsMap.Range(func(key, v interface{}) bool {
+					pointMap[key.(string)] = v
+					return true
+				})
 
 				res := Point{x: float32(pointMap["x"].(float64)), y: float32(pointMap["y"].(float64))}
 
@@ -195,10 +211,90 @@ var _ = Describe("JSON Reader", func() {
 			"point": pointReader,
 		}
 		reader := NewJSONReaderWithHandlers(buffer, customHandlers)
-		result := reader.Read()
+		result, err := reader.Read()
+		Expect(err).To(BeNil())
 
 		resultAsPoint, ok := result.(Point)
 		Expect(ok)
 		Expect(resultAsPoint).To(Equal(point))
 	})
 })
+
+var _ = Describe("Reading malformed input", func() {
+	var readError = func(str string) error {
+		reader := NewJSONReader(bytes.NewBufferString(str))
+		result, err := reader.Read()
+		Expect(result).To(BeNil())
+		return err
+	}
+
+	It("returns an error for truncated input", func() {
+		Expect(readError("[1,2")).To(Equal(io.ErrUnexpectedEOF))
+	})
+
+	It("returns an error for invalid JSON", func() {
+		Expect(readError("[1,}")).NotTo(BeNil())
+	})
+
+	It("returns io.EOF for empty input", func() {
+		Expect(readError("")).To(Equal(io.EOF))
+	})
+
+	It("returns an error for unknown cache codes", func() {
+		Expect(readError("[\"^ \",\"^0\",1]")).To(MatchError("Unknown cache code ^0"))
+	})
+
+	It("returns an error for values that cannot be decoded", func() {
+		Expect(readError("[\"~#'\",\"~iabc\"]")).NotTo(BeNil())
+		Expect(readError("[\"~#'\",\"~zNotANumber\"]")).NotTo(BeNil())
+	})
+
+	It("returns an error for invalid times", func() {
+		Expect(readError("[\"~#'\",\"~t2016-02-23\"]")).To(MatchError("Could not convert '2016-02-23' to a time"))
+		Expect(readError("[\"~#'\",\"~mnow\"]")).To(MatchError("Could not convert 'now' to a time"))
+		Expect(readError("[\"~#t\",1]")).NotTo(BeNil())
+	})
+
+	It("returns an error for invalid links", func() {
+		Expect(readError("[\"~#link\",[\"^ \",\"rel\",\"self\"]]")).To(MatchError("Value of href cannot be empty"))
+		Expect(readError("[\"~#link\",[\"/a\",\"self\"]]")).NotTo(BeNil())
+	})
+
+	It("returns an error for a tagged value with more than one representation", func() {
+		Expect(readError("[\"~#point\",1,2]")).NotTo(BeNil())
+	})
+
+	It("returns an error for a representation of the wrong type", func() {
+		Expect(readError("[\"~#:\",5]")).To(MatchError("Could not convert 5 to a keyword, expected a string"))
+		Expect(readError("[\"~#?\",[]]")).To(MatchError("Could not convert [] to a boolean, expected a string"))
+		Expect(readError("[\"~#'\",[\"~#n\",1]]")).NotTo(BeNil())
+	})
+
+	It("does not hide the panics of a custom handler", func() {
+		panickingHandler := ReadHandler{
+			FromRep: func(rep interface{}) (interface{}, error) {
+				return rep.([]int)[0], nil
+			},
+		}
+		reader := NewJSONReaderWithHandlers(bytes.NewBufferString("[\"~#point\",[]]"), ReadHandlerMap{"point": panickingHandler})
+		Expect(func() { reader.Read() }).To(Panic())
+	})
+
+	It("returns an error for truncated MessagePack input", func() {
+		reader := NewMsgpackReader(bytes.NewBuffer([]byte{0x92, 0x01}))
+		_, err := reader.Read()
+		Expect(err).NotTo(BeNil())
+	})
+
+	It("returns an error for a MessagePack map as array with a key without a value", func() {
+		reader := NewMsgpackReader(bytes.NewBuffer([]byte{0x92, 0x92, 0xa2, '^', ' ', 0xa1, 'a', 0x01}))
+		_, err := reader.Read()
+		Expect(err).To(MatchError("Map as array of 2 items has a key without a value"))
+	})
+
+	It("returns an error for a MessagePack array that claims more items than it has", func() {
+		reader := NewMsgpackReader(bytes.NewBuffer([]byte{0xdd, 0xff, 0xff, 0xff, 0xf0, 0x01, 0x02}))
+		_, err := reader.Read()
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,81 @@
+package transit_go
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Set", func() {
+	It("compares elements by value", func() {
+		set := NewSetFrom([]interface{}{
+			[]interface{}{1, 2},
+			NewMap("a", 1),
+			TaggedValue{Tag: "point", Rep: []interface{}{1, 2}},
+			Keyword("k"),
+		})
+		set.Add([]int{1, 2})
+		set.Add(int64(1))
+		set.Add(1)
+
+		Expect(set.Len()).To(Equal(5))
+		Expect(set.Contains([]int{1, 2})).To(BeTrue())
+		Expect(set.Contains(NewMap("a", 1))).To(BeTrue())
+		Expect(set.Contains(TaggedValue{Tag: "point", Rep: []int{1, 2}})).To(BeTrue())
+		Expect(set.Contains("k")).To(BeFalse())
+		Expect(set.Contains(uint16(1))).To(BeTrue())
+	})
+
+	It("removes elements", func() {
+		set := NewSetFrom([]interface{}{"a", "b", "c"})
+		Expect(set.Remove("a")).To(BeTrue())
+		Expect(set.Remove("a")).To(BeFalse())
+		Expect(set.Len()).To(Equal(2))
+		Expect(set.Contains("b")).To(BeTrue())
+		Expect(set.Contains("c")).To(BeTrue())
+
+		Expect(set.Remove("c")).To(BeTrue())
+		Expect(set.Items()).To(Equal([]interface{}{"b"}))
+	})
+
+	It("computes unions, intersections and differences", func() {
+		a := NewSetFrom([]interface{}{Keyword("read"), Keyword("write"), []int{1}})
+		b := NewSetFrom([]interface{}{Keyword("write"), Keyword("admin"), []interface{}{1}})
+
+		Expect(a.Union(b).Equal(NewSetFrom([]interface{}{Keyword("read"), Keyword("write"), Keyword("admin"), []int{1}}))).To(BeTrue())
+		Expect(a.Intersection(b).Equal(NewSetFrom([]interface{}{Keyword("write"), []int{1}}))).To(BeTrue())
+		Expect(a.Difference(b).Equal(NewSetFrom([]interface{}{Keyword("read")}))).To(BeTrue())
+		Expect(a.Len()).To(Equal(3))
+	})
+
+	It("is equal to a set with the same elements", func() {
+		Expect(NewSetFrom([]interface{}{1, "a"}).Equal(NewSetFrom([]interface{}{"a", 1}))).To(BeTrue())
+		Expect(NewSetFrom([]interface{}{1, "a"}).Equal(NewSetFrom([]interface{}{1}))).To(BeFalse())
+		Expect(NewSet().Equal(NewSet())).To(BeTrue())
+	})
+
+	It("roundtrips", func() {
+		set := NewSetFrom([]interface{}{Keyword("a"), []interface{}{1, 2}})
+		data, err := Marshal(set)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~#set\",[\"~:a\",[1,2]]]"))
+
+		var result interface{}
+		Expect(Unmarshal(data, &result)).To(Succeed())
+		Expect(result.(Set).Equal(set)).To(BeTrue())
+	})
+
+	It("reads large sets", func() {
+		var items []interface{}
+		for i := 0; i < 20000; i++ {
+			items = append(items, fmt.Sprintf("item-%d", i))
+		}
+		data, err := Marshal(NewSetFrom(items))
+		Expect(err).To(BeNil())
+
+		var result interface{}
+		Expect(Unmarshal(data, &result)).To(Succeed())
+		Expect(result.(Set).Len()).To(Equal(20000))
+	})
+})
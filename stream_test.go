@@ -0,0 +1,172 @@
+package transit_go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type failingWriter struct{}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+var _ = Describe("Encoder", func() {
+	It("writes consecutive values to any io.Writer", func() {
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		encoder := NewEncoder(gzipWriter)
+
+		Expect(encoder.Encode([]int{1, 2})).To(Succeed())
+		Expect(encoder.Encode("hello")).To(Succeed())
+		Expect(gzipWriter.Close()).To(Succeed())
+
+		gzipReader, err := gzip.NewReader(&compressed)
+		Expect(err).To(BeNil())
+		var buffer bytes.Buffer
+		_, err = buffer.ReadFrom(gzipReader)
+		Expect(err).To(BeNil())
+
+		Expect(buffer.String()).To(Equal("[1,2][\"~#'\",\"hello\"]"))
+	})
+
+	It("does not share the cache between values", func() {
+		var buffer bytes.Buffer
+		encoder := NewEncoder(&buffer)
+		m := map[string]int{"key": 1}
+
+		Expect(encoder.Encode(m)).To(Succeed())
+		Expect(encoder.Encode(m)).To(Succeed())
+
+		Expect(buffer.String()).To(Equal("[\"^ \",\"key\",1][\"^ \",\"key\",1]"))
+	})
+
+	It("writes JSON-Verbose", func() {
+		var buffer bytes.Buffer
+		encoder := NewJSONVerboseEncoder(&buffer)
+
+		Expect(encoder.Encode(map[string]int{"key": 1})).To(Succeed())
+		Expect(buffer.String()).To(Equal("{\"key\":1}"))
+	})
+
+	It("writes MessagePack", func() {
+		var buffer bytes.Buffer
+		encoder := NewMsgpackEncoder(&buffer)
+
+		Expect(encoder.Encode("hello")).To(Succeed())
+		Expect(NewMsgpackReader(&buffer).Read()).To(Equal("hello"))
+	})
+
+	It("does not write part of a value it cannot encode", func() {
+		var buffer bytes.Buffer
+		encoder := NewEncoder(&buffer)
+
+		Expect(encoder.Encode(map[string]interface{}{"key": make(chan int)})).NotTo(Succeed())
+		Expect(encoder.Encode([]int{2})).To(Succeed())
+		Expect(buffer.String()).To(Equal("[2]"))
+	})
+
+	It("does not write part of a value larger than its buffer", func() {
+		var buffer bytes.Buffer
+		encoder := NewEncoder(&buffer)
+
+		large := make([]interface{}, 10000)
+		for i := range large {
+			large[i] = i
+		}
+		Expect(encoder.Encode(append(large, make(chan int)))).NotTo(Succeed())
+		Expect(buffer.Len()).To(Equal(0))
+
+		msgpackEncoder := NewMsgpackEncoder(&buffer)
+		Expect(msgpackEncoder.Encode(append(large, make(chan int)))).NotTo(Succeed())
+		Expect(buffer.Len()).To(Equal(0))
+	})
+
+	It("returns the errors of the underlying writer", func() {
+		encoder := NewEncoder(failingWriter{})
+
+		err := encoder.Encode("hello")
+		Expect(err).To(MatchError("connection reset"))
+	})
+})
+
+var _ = Describe("Decoder", func() {
+	var decodeAll = func(decoder *Decoder) []interface{} {
+		var values []interface{}
+		for decoder.More() {
+			var v interface{}
+			Expect(decoder.Decode(&v)).To(Succeed())
+			values = append(values, v)
+		}
+		return values
+	}
+
+	It("reads consecutive values from any io.Reader", func() {
+		r, w := io.Pipe()
+		go func() {
+			encoder := NewEncoder(w)
+			for i := 0; i < 3; i++ {
+				encoder.Encode(map[string]int{"event": i})
+			}
+			w.Close()
+		}()
+
+		values := decodeAll(NewDecoder(r))
+		Expect(len(values)).To(Equal(3))
+		for i, v := range values {
+			Expect(v.(Map).Equal(NewMap("event", i))).To(BeTrue())
+		}
+	})
+
+	It("reads values separated by whitespace", func() {
+		decoder := NewDecoder(strings.NewReader("[\"~#'\",1]\n[\"~#'\",\"~:two\"]\n"))
+		Expect(decodeAll(decoder)).To(Equal([]interface{}{1, Keyword("two")}))
+	})
+
+	It("reads consecutive MessagePack values", func() {
+		var buffer bytes.Buffer
+		encoder := NewMsgpackEncoder(&buffer)
+		Expect(encoder.Encode("one")).To(Succeed())
+		Expect(encoder.Encode([]int{2})).To(Succeed())
+
+		Expect(decodeAll(NewMsgpackDecoder(&buffer))).To(Equal([]interface{}{"one", []interface{}{2}}))
+	})
+
+	It("returns io.EOF at the end of the stream", func() {
+		decoder := NewDecoder(strings.NewReader("[\"~#'\",1]"))
+		var v interface{}
+		Expect(decoder.Decode(&v)).To(Succeed())
+		Expect(decoder.Decode(&v)).To(Equal(io.EOF))
+	})
+
+	It("returns an error for a stray closing delimiter between values", func() {
+		decoder := NewDecoder(strings.NewReader("[1] ] [2]"))
+		var v interface{}
+		Expect(decoder.Decode(&v)).To(Succeed())
+		Expect(decoder.More()).To(BeTrue())
+		err := decoder.Decode(&v)
+		Expect(err).NotTo(BeNil())
+		Expect(err).NotTo(Equal(io.EOF))
+	})
+
+	It("decodes into typed values", func() {
+		decoder := NewDecoder(strings.NewReader("[\"~#'\",1]"))
+		var i int
+		Expect(decoder.Decode(&i)).To(Succeed())
+		Expect(i).To(Equal(1))
+	})
+
+	It("only decodes into a non-nil pointer", func() {
+		decoder := NewDecoder(strings.NewReader("[\"~#'\",1]"))
+		var i int
+		Expect(decoder.Decode(i)).NotTo(Succeed())
+		Expect(decoder.Decode(nil)).NotTo(Succeed())
+		Expect(decoder.More()).To(BeTrue())
+	})
+})
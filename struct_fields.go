@@ -0,0 +1,173 @@
+package transit_go
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structField describes how a field of a struct is written to and read from a
+// transit map. It is configured with a `transit` struct tag, holding the key
+// followed by options:
+//
+//	Name string `transit:"name"`           // written as "name" instead of "Name"
+//	Kind string `transit:"kind,keyword"`   // written as the keyword :kind
+//	Note string `transit:",omitempty"`     // skipped when empty
+//	Temp string `transit:"-"`              // never written or read
+type structField struct {
+	name      string
+	index     []int
+	keyword   bool
+	omitEmpty bool
+}
+
+type fieldList []structField
+
+// key returns the map key the field is written as
+func (f structField) key() interface{} {
+	if f.keyword {
+		return Keyword(f.name)
+	}
+	return f.name
+}
+
+func parseStructTag(tag string) (name string, keyword bool, omitEmpty bool) {
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		switch option {
+		case "keyword":
+			keyword = true
+		case "omitempty":
+			omitEmpty = true
+		}
+	}
+	return parts[0], keyword, omitEmpty
+}
+
+// structFields lists the exported fields of a struct type, including the fields
+// promoted from embedded structs. Fields of the outer struct hide promoted fields
+// with the same name. Every embedded struct type is walked once, so a struct can
+// embed (a pointer to) itself.
+func structFields(t reflect.Type) fieldList {
+	var fields fieldList
+	seen := make(map[string]bool)
+	visited := map[reflect.Type]bool{t: true}
+
+	var collect func(t reflect.Type, index []int)
+	var embedded [][]int
+	collect = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("transit")
+			if tag == "-" {
+				continue
+			}
+			name, keyword, omitEmpty := parseStructTag(tag)
+			fieldIndex := append(append([]int{}, index...), i)
+
+			if f.Anonymous && name == "" {
+				ft := f.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					embedded = append(embedded, fieldIndex)
+					continue
+				}
+			}
+			if f.PkgPath != "" {
+				// unexported
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if !seen[name] {
+				seen[name] = true
+				fields = append(fields, structField{name: name, index: fieldIndex, keyword: keyword, omitEmpty: omitEmpty})
+			}
+		}
+	}
+
+	collect(t, nil)
+	for len(embedded) > 0 {
+		current := embedded
+		embedded = nil
+		for _, index := range current {
+			ft := t.FieldByIndex(index).Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if visited[ft] {
+				continue
+			}
+			visited[ft] = true
+			collect(ft, index)
+		}
+	}
+	return fields
+}
+
+func (fields fieldList) lookup(name string) *structField {
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, name) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// structEntries returns the entries of the map a struct is written as, in the
+// order of the fields. Field values are written by their own handlers, so pointer
+// fields are written as the value they point to unless the pointer type marshals
+// itself.
+func structEntries(obj interface{}) mapEntries {
+	v := reflect.ValueOf(obj)
+	var entries mapEntries
+
+	for _, f := range structFields(v.Type()) {
+		fv, ok := fieldValue(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		entries = append(entries, mapEntry{key: f.key(), value: fv.Interface()})
+	}
+	return entries
+}
+
+// fieldValue returns the field of a (nested) struct, or false when it is promoted
+// through a nil embedded pointer
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package transit_go
+
+import (
+	"bytes"
+	"math/big"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type structAddress struct {
+	Street string `transit:"street,keyword"`
+	City   string `transit:"city,keyword"`
+}
+
+type structAudit struct {
+	CreatedBy string `transit:"created-by"`
+}
+
+type structPerson struct {
+	*structAudit
+	Name     string         `transit:"name,keyword"`
+	Nickname string         `transit:"nickname,keyword,omitempty"`
+	Age      int            `transit:"age"`
+	Address  *structAddress `transit:"address,keyword,omitempty"`
+	Password string         `transit:"-"`
+	Tags     []string
+	secret   string
+}
+
+type structNode struct {
+	*structNode
+	V int
+}
+
+var _ = Describe("Struct encoding", func() {
+	var buffer bytes.Buffer
+	writer := NewJSONWriter(&buffer)
+
+	AfterEach(func() {
+		buffer.Reset()
+	})
+
+	It("writes structs as maps in field order", func() {
+		type point struct {
+			X int
+			Y int
+		}
+		result := write(writer, point{X: 1, Y: 2})
+		Expect(result).To(Equal("[\"^ \",\"X\",1,\"Y\",2]"))
+	})
+
+	It("uses the names and options of transit tags", func() {
+		p := structPerson{Name: "JW", Age: 42, Password: "hunter2", Tags: []string{"a"}, secret: "s"}
+		result := write(writer, p)
+		Expect(result).To(Equal("[\"^ \",\"~:name\",\"JW\",\"age\",42,\"Tags\",[\"a\"]]"))
+	})
+
+	It("writes promoted fields of embedded structs and dereferences pointers", func() {
+		p := structPerson{
+			structAudit: &structAudit{CreatedBy: "admin"},
+			Name:        "JW",
+			Nickname:    "J",
+			Address:     &structAddress{Street: "Main", City: "Groenlo"},
+		}
+		result := write(writer, p)
+		Expect(result).To(Equal("[\"^ \",\"~:name\",\"JW\",\"~:nickname\",\"J\",\"age\",0," +
+			"\"~:address\",[\"^ \",\"~:street\",\"Main\",\"~:city\",\"Groenlo\"],\"Tags\",[],\"created-by\",\"admin\"]"))
+	})
+
+	It("writes and reads structs that embed a pointer to their own type", func() {
+		data, err := Marshal(structNode{structNode: &structNode{V: 1}, V: 2})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"^ \",\"V\",2]"))
+
+		var node structNode
+		Expect(Unmarshal([]byte("[\"^ \",\"V\",3]"), &node)).To(Succeed())
+		Expect(node.V).To(Equal(3))
+	})
+
+	It("writes pointers to structs, and nil pointers as null", func() {
+		var marshal = func(obj interface{}) string {
+			data, err := Marshal(obj)
+			Expect(err).To(BeNil())
+			return string(data)
+		}
+		address := &structAddress{Street: "Main", City: "Groenlo"}
+		written := "[\"^ \",\"~:street\",\"Main\",\"~:city\",\"Groenlo\"]"
+		Expect(marshal(address)).To(Equal(written))
+		Expect(marshal([]*structAddress{address, nil})).To(Equal("[" + written + ",null]"))
+		Expect(marshal(map[string]*structAddress{"home": address})).To(Equal("[\"^ \",\"home\"," + written + "]"))
+
+		var nilAddress *structAddress
+		Expect(marshal(nilAddress)).To(Equal("[\"~#'\",null]"))
+		age := 42
+		Expect(marshal(&age)).To(Equal("[\"~#'\",42]"))
+	})
+
+	It("writes nil pointers of types with a registered handler as null", func() {
+		var u *url.URL
+		var f *big.Float
+		var r *big.Rat
+		data, err := Marshal([]interface{}{u, f, r})
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[null,null,null]"))
+	})
+
+	It("reads back pointers it writes", func() {
+		addresses := []*structAddress{{Street: "Main", City: "Groenlo"}, nil}
+		data, err := Marshal(addresses)
+		Expect(err).To(BeNil())
+
+		var result []*structAddress
+		Expect(Unmarshal(data, &result)).To(Succeed())
+		Expect(result).To(Equal(addresses))
+	})
+
+	It("caches keyword keys", func() {
+		addresses := []interface{}{structAddress{Street: "a", City: "b"}, structAddress{Street: "c", City: "d"}}
+		result := write(writer, addresses)
+		Expect(result).To(Equal("[[\"^ \",\"~:street\",\"a\",\"~:city\",\"b\"],[\"^ \",\"^0\",\"c\",\"^1\",\"d\"]]"))
+	})
+
+	It("writes structs nested in maps and structs in other formats", func() {
+		m := map[string]interface{}{"address": structAddress{Street: "Main"}}
+
+		data, err := Marshal(m, WithFormat(FormatJSONVerbose))
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("{\"address\":{\"~:street\":\"Main\",\"~:city\":\"\"}}"))
+
+		data, err = Marshal(structAddress{Street: "Main"}, WithFormat(FormatMsgpack))
+		Expect(err).To(BeNil())
+		var address structAddress
+		Expect(Unmarshal(data, &address, WithFormat(FormatMsgpack))).To(Succeed())
+		Expect(address).To(Equal(structAddress{Street: "Main"}))
+	})
+
+	It("reads back what it writes", func() {
+		type event struct {
+			ID     int       `transit:"id,keyword"`
+			At     time.Time `transit:"at,keyword"`
+			Kind   string    `transit:"kind"`
+			Ignore string    `transit:"-"`
+		}
+		at := time.Unix(0, 1456231033010*int64(time.Millisecond)).UTC()
+		data, err := Marshal(event{ID: 3, At: at, Kind: "click", Ignore: "x"})
+		Expect(err).To(BeNil())
+
+		var result event
+		Expect(Unmarshal(data, &result)).To(Succeed())
+		Expect(result.ID).To(Equal(3))
+		Expect(result.At.Equal(at)).To(BeTrue())
+		Expect(result.Kind).To(Equal("click"))
+		Expect(result.Ignore).To(Equal(""))
+	})
+
+	It("reads into promoted fields of embedded structs", func() {
+		p := structPerson{structAudit: &structAudit{}}
+		Expect(Unmarshal([]byte("[\"^ \",\"created-by\",\"admin\",\"~:name\",\"JW\"]"), &p)).To(Succeed())
+		Expect(p.Name).To(Equal("JW"))
+		Expect(p.CreatedBy).To(Equal("admin"))
+	})
+
+	It("cannot read through a nil pointer to an unexported embedded struct", func() {
+		var p structPerson
+		err := Unmarshal([]byte("[\"^ \",\"created-by\",\"admin\"]"), &p)
+		Expect(err).To(MatchError("Cannot set embedded pointer to unexported struct transit_go.structAudit"))
+	})
+})
@@ -1,6 +1,12 @@
 package transit_go
 
-import "fmt"
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
 
 type TaggedValue struct {
 	Tag string
@@ -15,7 +21,14 @@ type Keyword string
 type Symbol string
 type Tag string
 
+// List is a transit list, like a Clojure list. It reads from and writes to
+// ["~#list",[...]], so it does not turn into an array on a round trip.
+type List []interface{}
+
 /* ========== Link type ==================== */
+
+// Link is a hypermedia link, written as ["~#link",{"href":"~r...","rel":...}].
+// Name, Prompt and Render are optional, Render is either "link" or "image".
 type Link struct {
 	Href   string
 	Rel    string
@@ -31,7 +44,7 @@ func NewLink(href, rel, name, prompt, render string) (Link, error) {
 	if rel == "" {
 		return Link{}, fmt.Errorf("Value of rel cannot be empty")
 	}
-	if render != "link" && render != "image" {
+	if render != "" && render != "link" && render != "image" {
 		return Link{}, fmt.Errorf("Value of render should be either 'link' or 'image'")
 	}
 	return Link{Href: href, Rel: rel, Name: name, Prompt: prompt, Render: render}, nil
@@ -41,22 +54,147 @@ func NewLinkFromMap(linkMap map[string]string) (Link, error) {
 	return NewLink(linkMap["href"], linkMap["rel"], linkMap["name"], linkMap["prompt"], linkMap["render"])
 }
 
+/* ========== BigDecimal type ==================== */
+
+// BigDecimal is an arbitrary-precision decimal number with the value
+// Unscaled × 10^-Scale, like a java.math.BigDecimal. It is written as and read
+// from ~f strings without loss of precision. The zero value is 0.
+type BigDecimal struct {
+	Unscaled *big.Int
+	Scale    int32
+}
+
+func NewBigDecimal(unscaled *big.Int, scale int32) BigDecimal {
+	return BigDecimal{Unscaled: unscaled, Scale: scale}
+}
+
+// ParseBigDecimal parses a decimal number like "12.50", "-0.001" or "1.5E+10".
+// The scale is the number of digits after the point, minus the exponent.
+func ParseBigDecimal(str string) (BigDecimal, error) {
+	digits := str
+	var exponent int64
+	if i := strings.IndexAny(digits, "eE"); i >= 0 {
+		exp, err := strconv.ParseInt(digits[i+1:], 10, 32)
+		if err != nil {
+			return BigDecimal{}, fmt.Errorf("Could not convert '%s' to BigDecimal", str)
+		}
+		exponent = exp
+		digits = digits[:i]
+	}
+
+	var fractionDigits int64
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		fractionDigits = int64(len(digits) - i - 1)
+		digits = digits[:i] + digits[i+1:]
+	}
+
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	scale := fractionDigits - exponent
+	if !ok || scale != int64(int32(scale)) {
+		return BigDecimal{}, fmt.Errorf("Could not convert '%s' to BigDecimal", str)
+	}
+	return BigDecimal{Unscaled: unscaled, Scale: int32(scale)}, nil
+}
+
+func (d BigDecimal) unscaled() *big.Int {
+	if d.Unscaled == nil {
+		return new(big.Int)
+	}
+	return d.Unscaled
+}
+
+// String formats the number like java.math.BigDecimal.toString does, using an
+// exponent only for negative scales and very small numbers.
+func (d BigDecimal) String() string {
+	unscaled := d.unscaled()
+	digits := new(big.Int).Abs(unscaled).String()
+	sign := ""
+	if unscaled.Sign() < 0 {
+		sign = "-"
+	}
+
+	scale := int64(d.Scale)
+	adjusted := int64(len(digits)-1) - scale
+	if scale == 0 {
+		return sign + digits
+	} else if scale > 0 && adjusted >= -6 {
+		if int64(len(digits)) <= scale {
+			digits = strings.Repeat("0", int(scale)-len(digits)+1) + digits
+		}
+		point := len(digits) - int(scale)
+		return sign + digits[:point] + "." + digits[point:]
+	}
+
+	mantissa := digits[:1]
+	if len(digits) > 1 {
+		mantissa += "." + digits[1:]
+	}
+	exponent := strconv.FormatInt(adjusted, 10)
+	if adjusted >= 0 {
+		exponent = "+" + exponent
+	}
+	return sign + mantissa + "E" + exponent
+}
+
+// Rat returns the exact value of the number as a fraction
+func (d BigDecimal) Rat() *big.Rat {
+	exponent := int64(d.Scale)
+	if exponent < 0 {
+		exponent = -exponent
+	}
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(exponent), nil)
+
+	num := new(big.Int).Set(d.unscaled())
+	denom := big.NewInt(1)
+	if d.Scale > 0 {
+		denom = power
+	} else {
+		num.Mul(num, power)
+	}
+	return new(big.Rat).SetFrac(num, denom)
+}
+
+// Cmp compares the values of two numbers regardless of their scale, so 1.0 and
+// 1.00 are equal. The result is -1, 0 or +1 like big.Int.Cmp.
+func (d BigDecimal) Cmp(other BigDecimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// Float64 returns the nearest float64 value of the number
+func (d BigDecimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
 /* ==========  Set type ==================== */
 
+// Set is a set of transit values. Like the keys of a Map, elements are compared
+// by value, so composite values like slices and maps can be elements as well.
 type Set interface {
 	Add(elem interface{}) interface{}
 	Contains(elem interface{}) bool
 	Remove(elem interface{}) bool
 	Items() []interface{}
 	Len() int
+
+	// Union returns a new set with the elements of both sets
+	Union(other Set) Set
+	// Intersection returns a new set with the elements that are in both sets
+	Intersection(other Set) Set
+	// Difference returns a new set with the elements that are not in other
+	Difference(other Set) Set
+	// Equal reports whether both sets have the same elements
+	Equal(other Set) bool
 }
 
+// setStruct keeps its elements in a slice, indexed by their hash
 type setStruct struct {
-	backingMap map[*MapKey]bool
+	items []interface{}
+	index map[uint64][]int
 }
 
 func NewSet() Set {
-	return setStruct{backingMap: make(map[*MapKey]bool)}
+	return &setStruct{index: make(map[uint64][]int)}
 }
 
 func NewSetFrom(elements []interface{}) Set {
@@ -67,42 +205,122 @@ func NewSetFrom(elements []interface{}) Set {
 	return set
 }
 
-func (s setStruct) Add(elem interface{}) interface{} {
-	if !s.Contains(elem) {
-		s.backingMap[newMapKey(elem)] = true
+// find returns the position of elem in items, or -1
+func (s *setStruct) find(elem interface{}, hash uint64) int {
+	for _, i := range s.index[hash] {
+		if valueEquals(s.items[i], elem) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *setStruct) Add(elem interface{}) interface{} {
+	hash := valueHash(elem)
+	if s.find(elem, hash) < 0 {
+		s.index[hash] = append(s.index[hash], len(s.items))
+		s.items = append(s.items, elem)
 	}
 	return elem
 }
 
-func (s setStruct) Contains(elem interface{}) bool {
-	for mapKey, _ := range s.backingMap {
-		if mapKey.Key == elem {
-			return true
-		}
+func (s *setStruct) Contains(elem interface{}) bool {
+	return s.find(elem, valueHash(elem)) >= 0
+}
+
+func (s *setStruct) Remove(elem interface{}) bool {
+	hash := valueHash(elem)
+	i := s.find(elem, hash)
+	if i < 0 {
+		return false
 	}
-	return false
+	s.removeFromIndex(hash, i)
+
+	// move the last element into the gap
+	last := len(s.items) - 1
+	if i != last {
+		lastHash := valueHash(s.items[last])
+		s.removeFromIndex(lastHash, last)
+		s.index[lastHash] = append(s.index[lastHash], i)
+		s.items[i] = s.items[last]
+	}
+	s.items[last] = nil
+	s.items = s.items[:last]
+	return true
 }
 
-func (s setStruct) Remove(elem interface{}) bool {
-	for mapKey, _ := range s.backingMap {
-		if mapKey.Key == elem {
-			delete(s.backingMap, mapKey)
-			return true
+func (s *setStruct) removeFromIndex(hash uint64, position int) {
+	positions := s.index[hash]
+	for j, p := range positions {
+		if p == position {
+			positions = append(positions[:j], positions[j+1:]...)
+			break
 		}
 	}
-	return false
+	if len(positions) == 0 {
+		delete(s.index, hash)
+	} else {
+		s.index[hash] = positions
+	}
+}
+
+func (s *setStruct) Items() []interface{} {
+	return append([]interface{}(nil), s.items...)
+}
+
+func (s *setStruct) Len() int {
+	return len(s.items)
+}
+
+func (s *setStruct) Union(other Set) Set {
+	result := NewSetFrom(s.items)
+	for _, elem := range other.Items() {
+		result.Add(elem)
+	}
+	return result
 }
 
-func (s setStruct) Items() []interface{} {
-	var items []interface{}
+func (s *setStruct) Intersection(other Set) Set {
+	result := NewSet()
+	for _, elem := range s.items {
+		if other.Contains(elem) {
+			result.Add(elem)
+		}
+	}
+	return result
+}
 
-	for mapKey, _ := range s.backingMap {
-		items = append(items, mapKey.Key)
+func (s *setStruct) Difference(other Set) Set {
+	result := NewSet()
+	for _, elem := range s.items {
+		if !other.Contains(elem) {
+			result.Add(elem)
+		}
 	}
+	return result
+}
 
-	return items
+func (s *setStruct) Equal(other Set) bool {
+	if s.Len() != other.Len() {
+		return false
+	}
+	for _, elem := range s.items {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+	return true
 }
 
-func (s setStruct) Len() int {
-	return len(s.backingMap)
+func (s *setStruct) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("set[")
+	for i, elem := range s.items {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		fmt.Fprintf(&buf, "%v", elem)
+	}
+	buf.WriteString("]")
+	return buf.String()
 }
@@ -0,0 +1,257 @@
+package transit_go
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// UnmarshalTypeError describes a transit value that could not be stored in a Go
+// value of a specific type.
+type UnmarshalTypeError struct {
+	Value string       // the Go type of the decoded transit value
+	Type  reflect.Type // the type of the Go value it could not be assigned to
+	Path  string       // the location of the value, like "items[2].name"
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("Cannot decode %s into Go value of type %s", e.Value, e.Type)
+	}
+	return fmt.Sprintf("Cannot decode %s into Go value of type %s at %s", e.Value, e.Type, e.Path)
+}
+
+// assignValue stores a value as returned by the parser in dst
+func assignValue(dst reflect.Value, src interface{}, path string) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if dst.Kind() != reflect.Interface && dst.CanAddr() {
+		if u, ok := dst.Addr().Interface().(TransitUnmarshaler); ok {
+			return unmarshalTransit(u, src)
+		}
+	}
+
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Type().AssignableTo(dst.Type()) {
+		dst.Set(srcValue)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assignValue(dst.Elem(), src, path)
+	case reflect.Bool:
+		if b, ok := src.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.String:
+		if srcValue.Kind() == reflect.String {
+			dst.SetString(srcValue.String())
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := srcToInt64(src); ok && !dst.OverflowInt(i) {
+			dst.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, ok := srcToUint64(src); ok && !dst.OverflowUint(u) {
+			dst.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := srcToFloat64(src); ok && !dst.OverflowFloat(f) {
+			dst.SetFloat(f)
+			return nil
+		}
+	case reflect.Slice:
+		if items, ok := srcToItems(src); ok {
+			slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+			for i, item := range items {
+				err := assignValue(slice.Index(i), item, fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return err
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+	case reflect.Array:
+		if items, ok := srcToItems(src); ok && len(items) == dst.Len() {
+			for i, item := range items {
+				err := assignValue(dst.Index(i), item, fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if m, ok := src.(Map); ok {
+			return assignMap(dst, m, path)
+		}
+	case reflect.Struct:
+		if m, ok := src.(Map); ok {
+			return assignStruct(dst, m, path)
+		}
+	}
+
+	return &UnmarshalTypeError{Value: srcValue.Type().String(), Type: dst.Type(), Path: path}
+}
+
+func assignMap(dst reflect.Value, m Map, path string) error {
+	mapType := dst.Type()
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMap(mapType))
+	}
+
+	var err error
+	m.Range(func(mapKey, val interface{}) bool {
+		keyPath := fmt.Sprintf("%s[%v]", path, mapKey)
+
+		key := reflect.New(mapType.Key()).Elem()
+		err = assignValue(key, mapKey, keyPath)
+		if err != nil {
+			return false
+		}
+
+		elem := reflect.New(mapType.Elem()).Elem()
+		err = assignValue(elem, val, keyPath)
+		if err != nil {
+			return false
+		}
+		dst.SetMapIndex(key, elem)
+		return true
+	})
+	return err
+}
+
+// assignStruct sets the fields of a struct from the entries of a map. Keys are
+// matched to field names exactly first, and case-insensitively otherwise. Entries
+// without a matching field are ignored.
+func assignStruct(dst reflect.Value, m Map, path string) error {
+	fields := structFields(dst.Type())
+
+	var err error
+	m.Range(func(mapKey, val interface{}) bool {
+		name, ok := keyName(mapKey)
+		if !ok {
+			return true
+		}
+
+		f := fields.lookup(name)
+		if f == nil {
+			return true
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		var fieldValue reflect.Value
+		fieldValue, err = fieldByIndex(dst, f.index)
+		if err != nil {
+			return false
+		}
+		err = assignValue(fieldValue, val, fieldPath)
+		return err == nil
+	})
+	return err
+}
+
+// fieldByIndex returns the field of a (nested) struct, allocating embedded
+// struct pointers on the way
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("Cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+func keyName(key interface{}) (string, bool) {
+	switch k := key.(type) {
+	case string:
+		return k, true
+	case Keyword:
+		return string(k), true
+	case Symbol:
+		return string(k), true
+	}
+	return "", false
+}
+
+func srcToInt64(src interface{}) (int64, bool) {
+	switch i := src.(type) {
+	case int:
+		return int64(i), true
+	case int64:
+		return i, true
+	case *big.Int:
+		return i.Int64(), i.IsInt64()
+	}
+	return 0, false
+}
+
+func srcToUint64(src interface{}) (uint64, bool) {
+	switch i := src.(type) {
+	case int:
+		return uint64(i), i >= 0
+	case int64:
+		return uint64(i), i >= 0
+	case *big.Int:
+		return i.Uint64(), i.IsUint64()
+	}
+	return 0, false
+}
+
+func srcToFloat64(src interface{}) (float64, bool) {
+	switch f := src.(type) {
+	case float64:
+		return f, true
+	case int:
+		return float64(f), true
+	case int64:
+		return float64(f), true
+	case *big.Int:
+		result, _ := new(big.Float).SetInt(f).Float64()
+		return result, !math.IsInf(result, 0)
+	case BigDecimal:
+		result := f.Float64()
+		return result, !math.IsInf(result, 0)
+	case *big.Rat:
+		result, _ := f.Float64()
+		return result, !math.IsInf(result, 0)
+	}
+	return 0, false
+}
+
+// srcToItems returns the elements of a decoded array, list or set
+func srcToItems(src interface{}) ([]interface{}, bool) {
+	switch items := src.(type) {
+	case []interface{}:
+		return items, true
+	case List:
+		return items, true
+	case Set:
+		return items.Items(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,198 @@
+package transit_go
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type unmarshalAddress struct {
+	Street string
+	Number int
+}
+
+type unmarshalBase struct {
+	ID int64
+}
+
+type unmarshalPerson struct {
+	unmarshalBase
+	Name      string
+	Age       uint8
+	Tags      []string
+	Address   *unmarshalAddress
+	Scores    map[string]float64
+	Born      time.Time
+	private   string
+	Addresses []unmarshalAddress
+}
+
+var _ = Describe("Unmarshal into typed values", func() {
+	It("decodes scalars", func() {
+		var s string
+		Expect(Unmarshal([]byte("[\"~#'\",\"hello\"]"), &s)).To(Succeed())
+		Expect(s).To(Equal("hello"))
+
+		var i int32
+		Expect(Unmarshal([]byte("[\"~#'\",42]"), &i)).To(Succeed())
+		Expect(i).To(Equal(int32(42)))
+
+		var f float64
+		Expect(Unmarshal([]byte("[\"~#'\",42]"), &f)).To(Succeed())
+		Expect(f).To(Equal(42.0))
+
+		var b bool
+		Expect(Unmarshal([]byte("[\"~#'\",true]"), &b)).To(Succeed())
+		Expect(b).To(BeTrue())
+	})
+
+	It("decodes keywords into strings and keywords", func() {
+		var s string
+		Expect(Unmarshal([]byte("[\"~#'\",\"~:name\"]"), &s)).To(Succeed())
+		Expect(s).To(Equal("name"))
+
+		var k Keyword
+		Expect(Unmarshal([]byte("[\"~#'\",\"~:name\"]"), &k)).To(Succeed())
+		Expect(k).To(Equal(Keyword("name")))
+	})
+
+	It("decodes times and big integers", func() {
+		var t time.Time
+		Expect(Unmarshal([]byte("[\"~#'\",\"~m1456231033010\"]"), &t)).To(Succeed())
+		Expect(t.Equal(time.Unix(0, 1456231033010*int64(time.Millisecond)))).To(BeTrue())
+
+		var n *big.Int
+		Expect(Unmarshal([]byte("[\"~#'\",\"~n123456789012345678901234567890\"]"), &n)).To(Succeed())
+		Expect(n.String()).To(Equal("123456789012345678901234567890"))
+
+		var u uint64
+		Expect(Unmarshal([]byte("[\"~#'\",\"~n18446744073709551615\"]"), &u)).To(Succeed())
+		Expect(u).To(Equal(uint64(math.MaxUint64)))
+	})
+
+	It("decodes times in UTC, or in the configured location", func() {
+		var t time.Time
+		Expect(Unmarshal([]byte("[\"~#'\",\"~t2016-02-23T13:37:13.010+01:00\"]"), &t)).To(Succeed())
+		Expect(t).To(Equal(time.Date(2016, 2, 23, 12, 37, 13, 10*int(time.Millisecond), time.UTC)))
+
+		amsterdam := time.FixedZone("CET", 3600)
+		Expect(Unmarshal([]byte("[\"~#'\",\"~m1456231033010\"]"), &t, WithLocation(amsterdam))).To(Succeed())
+		Expect(t.Location()).To(Equal(amsterdam))
+		Expect(t.Hour()).To(Equal(13))
+
+		Expect(Unmarshal([]byte("[\"~#'\",\"~t2016-02-23T12:37:13.010Z\"]"), &t, WithLocation(amsterdam))).To(Succeed())
+		Expect(t.Location()).To(Equal(amsterdam))
+	})
+
+	It("decodes typed slices and arrays", func() {
+		var ints []int
+		Expect(Unmarshal([]byte("[1,2,3]"), &ints)).To(Succeed())
+		Expect(ints).To(Equal([]int{1, 2, 3}))
+
+		var arr [2]string
+		Expect(Unmarshal([]byte("[\"a\",\"b\"]"), &arr)).To(Succeed())
+		Expect(arr).To(Equal([2]string{"a", "b"}))
+	})
+
+	It("decodes sets and lists into slices", func() {
+		var ints []int
+		Expect(Unmarshal([]byte("[\"~#set\",[7]]"), &ints)).To(Succeed())
+		Expect(ints).To(Equal([]int{7}))
+
+		Expect(Unmarshal([]byte("[\"~#list\",[1,2]]"), &ints)).To(Succeed())
+		Expect(ints).To(Equal([]int{1, 2}))
+	})
+
+	It("decodes typed maps", func() {
+		var m map[string]int
+		Expect(Unmarshal([]byte("[\"^ \",\"a\",1,\"b\",2]"), &m)).To(Succeed())
+		Expect(m).To(Equal(map[string]int{"a": 1, "b": 2}))
+
+		var byKeyword map[Keyword][]bool
+		Expect(Unmarshal([]byte("[\"^ \",\"~:on\",[true]]"), &byKeyword)).To(Succeed())
+		Expect(byKeyword).To(Equal(map[Keyword][]bool{"on": {true}}))
+
+		var byInt map[int]string
+		Expect(Unmarshal([]byte("[\"^ \",\"~i1\",\"one\"]"), &byInt)).To(Succeed())
+		Expect(byInt).To(Equal(map[int]string{1: "one"}))
+	})
+
+	It("decodes maps into structs", func() {
+		data := "[\"^ \",\"~:id\",7,\"~:name\",\"JW\",\"~:age\",42,\"~:tags\",[\"a\"]," +
+			"\"~:address\",[\"^ \",\"~:street\",\"Main\",\"~:number\",1]," +
+			"\"~:scores\",[\"^ \",\"x\",1.5],\"~:born\",\"~m0\",\"~:private\",\"p\",\"~:unknown\",1," +
+			"\"~:addresses\",[[\"^ \",\"~:street\",\"Side\"]]]"
+
+		var p unmarshalPerson
+		Expect(Unmarshal([]byte(data), &p)).To(Succeed())
+		Expect(p.ID).To(Equal(int64(7)))
+		Expect(p.Name).To(Equal("JW"))
+		Expect(p.Age).To(Equal(uint8(42)))
+		Expect(p.Tags).To(Equal([]string{"a"}))
+		Expect(p.Address).To(Equal(&unmarshalAddress{Street: "Main", Number: 1}))
+		Expect(p.Scores).To(Equal(map[string]float64{"x": 1.5}))
+		Expect(p.Born.Equal(time.Unix(0, 0))).To(BeTrue())
+		Expect(p.private).To(Equal(""))
+		Expect(p.Addresses).To(Equal([]unmarshalAddress{{Street: "Side"}}))
+	})
+
+	It("sets nil values to the zero value", func() {
+		s := "set"
+		ptr := &s
+		Expect(Unmarshal([]byte("[\"~#'\",null]"), &ptr)).To(Succeed())
+		Expect(ptr).To(BeNil())
+	})
+
+	It("decodes MessagePack into typed values", func() {
+		data, err := Marshal(map[string][]int{"key": {1, 2}}, WithFormat(FormatMsgpack))
+		Expect(err).To(BeNil())
+
+		var m map[string][]int
+		Expect(Unmarshal(data, &m, WithFormat(FormatMsgpack))).To(Succeed())
+		Expect(m).To(Equal(map[string][]int{"key": {1, 2}}))
+	})
+
+	Context("with values that do not fit", func() {
+		It("returns an UnmarshalTypeError for mismatched types", func() {
+			var i int
+			err := Unmarshal([]byte("[\"~#'\",\"hello\"]"), &i)
+			Expect(err).To(Equal(&UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(0)}))
+			Expect(err).To(MatchError("Cannot decode string into Go value of type int"))
+		})
+
+		It("returns an UnmarshalTypeError for overflowing numbers", func() {
+			var i int8
+			err := Unmarshal([]byte("[\"~#'\",300]"), &i)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+
+			var u uint
+			err = Unmarshal([]byte("[\"~#'\",-1]"), &u)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+		})
+
+		It("does not truncate floats", func() {
+			var i int
+			err := Unmarshal([]byte("[\"~#'\",1.5]"), &i)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+		})
+
+		It("reports the path of the value", func() {
+			var p unmarshalPerson
+			err := Unmarshal([]byte("[\"^ \",\"tags\",[\"a\",2]]"), &p)
+			Expect(err).To(MatchError("Cannot decode int into Go value of type string at tags[1]"))
+
+			err = Unmarshal([]byte("[\"^ \",\"addresses\",[[\"^ \",\"number\",\"one\"]]]"), &p)
+			Expect(err).To(MatchError("Cannot decode string into Go value of type int at addresses[0].number"))
+		})
+
+		It("returns an error for arrays of the wrong length", func() {
+			var arr [3]int
+			err := Unmarshal([]byte("[1,2]"), &arr)
+			Expect(err).To(BeAssignableToTypeOf(&UnmarshalTypeError{}))
+		})
+	})
+})
@@ -6,7 +6,7 @@ import (
 )
 
 func mapToMapEntries(m interface{}) (mapEntries, error) {
-	entries := NewSet()
+	var entries mapEntries
 
 	mapAsValue := reflect.ValueOf(m)
 
@@ -17,7 +17,7 @@ func mapToMapEntries(m interface{}) (mapEntries, error) {
 	keys := mapAsValue.MapKeys()
 	for _, key := range keys {
 		value := mapAsValue.MapIndex(key).Interface()
-		entries.Add(mapEntry{key: key.Interface(), value: value})
+		entries = append(entries, mapEntry{key: key.Interface(), value: value})
 	}
 
 	return entries, nil
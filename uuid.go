@@ -0,0 +1,138 @@
+package transit_go
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/twinj/uuid"
+)
+
+// UUIDAdapter connects a UUID type to transit. UUIDs are written as ~u strings,
+// or in MessagePack as ["~#u",[hi,lo]], the two big-endian int64 halves of the
+// UUID. By default UUIDs of github.com/twinj/uuid are used, any other type can be
+// used by passing its adapter to WithUUIDAdapter.
+type UUIDAdapter struct {
+	// Type is the type of the UUID values that are written
+	Type reflect.Type
+	// FromBytes creates a UUID value from its 16 bytes
+	FromBytes func(b [16]byte) interface{}
+	// ToBytes returns the 16 bytes of a UUID value of Type
+	ToBytes func(obj interface{}) [16]byte
+}
+
+// TwinjUUIDAdapter is the default adapter, for github.com/twinj/uuid
+func TwinjUUIDAdapter() UUIDAdapter {
+	return UUIDAdapter{
+		Type: reflect.TypeOf(uuid.NewV4()),
+		FromBytes: func(b [16]byte) interface{} {
+			return uuid.New(b[:])
+		},
+		ToBytes: func(obj interface{}) [16]byte {
+			var b [16]byte
+			copy(b[:], obj.(uuid.UUID).Bytes())
+			return b
+		},
+	}
+}
+
+// ByteArrayUUIDAdapter is the adapter of a UUID type that is a [16]byte, like
+// github.com/google/uuid.UUID or github.com/gofrs/uuid.UUID. It panics if typ
+// is not a [16]byte.
+func ByteArrayUUIDAdapter(typ reflect.Type) UUIDAdapter {
+	bytesType := reflect.TypeOf([16]byte{})
+	if !bytesType.ConvertibleTo(typ) || !typ.ConvertibleTo(bytesType) {
+		panic(fmt.Sprintf("ByteArrayUUIDAdapter expects a [16]byte type, got %v", typ))
+	}
+	return UUIDAdapter{
+		Type: typ,
+		FromBytes: func(b [16]byte) interface{} {
+			return reflect.ValueOf(b).Convert(typ).Interface()
+		},
+		ToBytes: func(obj interface{}) [16]byte {
+			return reflect.ValueOf(obj).Convert(bytesType).Interface().([16]byte)
+		},
+	}
+}
+
+// WriteHandler returns the handler that writes values of the Type of the adapter
+func (a UUIDAdapter) WriteHandler() WriteHandler {
+	return WriteHandler{
+		Name: "UUID Write Handler",
+		Tag:  func(obj interface{}) string { return "u" },
+		Rep: func(obj interface{}) interface{} {
+			b := a.ToBytes(obj)
+			return []int64{
+				int64(binary.BigEndian.Uint64(b[:8])),
+				int64(binary.BigEndian.Uint64(b[8:])),
+			}
+		},
+		StringRep: func(obj interface{}) *string {
+			str := formatUUID(a.ToBytes(obj))
+			return &str
+		},
+	}
+}
+
+// ReadHandler returns the handler that reads UUIDs as values created by the adapter
+func (a UUIDAdapter) ReadHandler() ReadHandler {
+	return ReadHandler{
+		Name: "UUID",
+		FromRep: func(rep interface{}) (interface{}, error) {
+			var b [16]byte
+			switch r := rep.(type) {
+			case string:
+				var err error
+				b, err = parseUUID(r)
+				if err != nil {
+					return nil, err
+				}
+			case []interface{}:
+				if len(r) != 2 {
+					return nil, fmt.Errorf("Could not convert %v to a UUID", rep)
+				}
+				hi, hiOk := uuidPart(r[0])
+				lo, loOk := uuidPart(r[1])
+				if !hiOk || !loOk {
+					return nil, fmt.Errorf("Could not convert %v to a UUID", rep)
+				}
+				binary.BigEndian.PutUint64(b[:8], uint64(hi))
+				binary.BigEndian.PutUint64(b[8:], uint64(lo))
+			default:
+				return nil, fmt.Errorf("Could not convert %v to a UUID", rep)
+			}
+			return a.FromBytes(b), nil
+		},
+	}
+}
+
+// uuidPart returns one of the two int64 halves of a UUID
+func uuidPart(part interface{}) (int64, bool) {
+	switch i := part.(type) {
+	case int:
+		return int64(i), true
+	case int64:
+		return i, true
+	}
+	return 0, false
+}
+
+// formatUUID formats a UUID like dda5a83f-8f9d-4194-ae88-5745c8ca94a7
+func formatUUID(b [16]byte) string {
+	str := hex.EncodeToString(b[:])
+	return str[0:8] + "-" + str[8:12] + "-" + str[12:16] + "-" + str[16:20] + "-" + str[20:]
+}
+
+func parseUUID(str string) ([16]byte, error) {
+	var b [16]byte
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return b, fmt.Errorf("Could not convert '%s' to a UUID", str)
+	}
+	_, err := hex.Decode(b[:], []byte(strings.Replace(str, "-", "", -1)))
+	if err != nil {
+		return b, fmt.Errorf("Could not convert '%s' to a UUID", str)
+	}
+	return b, nil
+}
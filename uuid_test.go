@@ -0,0 +1,63 @@
+package transit_go
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/twinj/uuid"
+)
+
+// byteUUID is a UUID type like the one of github.com/google/uuid
+type byteUUID [16]byte
+
+var _ = Describe("UUIDs", func() {
+	id := byteUUID{0xdd, 0xa5, 0xa8, 0x3f, 0x8f, 0x9d, 0x41, 0x94, 0xae, 0x88, 0x57, 0x45, 0xc8, 0xca, 0x94, 0xa7}
+	adapter := WithUUIDAdapter(ByteArrayUUIDAdapter(reflect.TypeOf(byteUUID{})))
+
+	It("reads the string and the two integer representation", func() {
+		expected, err := uuid.Parse("dda5a83f-8f9d-4194-ae88-5745c8ca94a7")
+		Expect(err).To(BeNil())
+
+		for _, str := range []string{
+			"[\"~#'\",\"~udda5a83f-8f9d-4194-ae88-5745c8ca94a7\"]",
+			"[\"~#u\",[-2475387429224365676,-5870346157044362073]]",
+		} {
+			var result interface{}
+			Expect(Unmarshal([]byte(str), &result)).To(Succeed())
+			Expect(result).To(Equal(expected))
+		}
+	})
+
+	It("writes and reads UUID types of an adapter", func() {
+		data, err := Marshal(id, adapter)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal("[\"~#'\",\"~udda5a83f-8f9d-4194-ae88-5745c8ca94a7\"]"))
+
+		var result interface{}
+		Expect(Unmarshal(data, &result, adapter)).To(Succeed())
+		Expect(result).To(Equal(id))
+	})
+
+	It("roundtrips UUID types of an adapter in MessagePack", func() {
+		data, err := Marshal([]byteUUID{id}, adapter, WithFormat(FormatMsgpack))
+		Expect(err).To(BeNil())
+
+		var result []byteUUID
+		Expect(Unmarshal(data, &result, adapter, WithFormat(FormatMsgpack))).To(Succeed())
+		Expect(result).To(Equal([]byteUUID{id}))
+	})
+
+	It("panics for an adapter of a type that is not a [16]byte", func() {
+		for _, typ := range []reflect.Type{reflect.TypeOf(""), reflect.TypeOf([]byte{}), reflect.TypeOf([8]byte{})} {
+			Expect(func() { ByteArrayUUIDAdapter(typ) }).To(PanicWith(ContainSubstring("expects a [16]byte type")))
+		}
+	})
+
+	It("returns an error for invalid UUIDs", func() {
+		var result interface{}
+		Expect(Unmarshal([]byte("[\"~#'\",\"~unot-a-uuid\"]"), &result)).To(MatchError("Could not convert 'not-a-uuid' to a UUID"))
+		Expect(Unmarshal([]byte("[\"~#'\",\"~udda5a83f-8f9d-4194-ae88-5745c8ca94zz\"]"), &result)).NotTo(Succeed())
+		Expect(Unmarshal([]byte("[\"~#u\",[1]]"), &result)).NotTo(Succeed())
+	})
+})
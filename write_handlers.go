@@ -1,16 +1,12 @@
 package transit_go
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 	"math"
 	"math/big"
 	"reflect"
 	"strconv"
 	"time"
-
-	"github.com/twinj/uuid"
 )
 
 func arrayWriteHandler() WriteHandler {
@@ -52,23 +48,34 @@ func keywordWriteHandler() WriteHandler {
 		Name: "Keyword Write Handler",
 		Tag:  func(obj interface{}) string { return ":" },
 		Rep: func(obj interface{}) interface{} {
-			strRep, _ := obj.(string)
-			return strRep[1:len(strRep)]
+			return string(obj.(Keyword))
+		},
+		StringRep: func(obj interface{}) *string {
+			str := string(obj.(Keyword))
+			return &str
+		},
+	}
+}
+
+func symbolWriteHandler() WriteHandler {
+	return WriteHandler{
+		Name: "Symbol Write Handler",
+		Tag:  func(obj interface{}) string { return "$" },
+		Rep: func(obj interface{}) interface{} {
+			return string(obj.(Symbol))
 		},
 		StringRep: func(obj interface{}) *string {
-			strRep, _ := obj.(string)
-			str := strRep[1:len(strRep)]
+			str := string(obj.(Symbol))
 			return &str
 		},
 	}
 }
 
-func stringableKeys(keys []reflect.Value, tagProvider TagProvider) bool {
+func stringableKeys(keys []interface{}, tagProvider TagProvider) bool {
 	for _, key := range keys {
-		actualKey := key.Interface()
-		tag := tagProvider.GetTag(actualKey)
+		tag := tagProvider.GetTag(key)
 
-		_, keyIsAString := actualKey.(string)
+		_, keyIsAString := key.(string)
 
 		if len(tag) > 1 {
 			return false
@@ -79,13 +86,18 @@ func stringableKeys(keys []reflect.Value, tagProvider TagProvider) bool {
 	return true
 }
 
+func mapKeys(obj interface{}) []interface{} {
+	var keys []interface{}
+	for _, key := range reflect.ValueOf(obj).MapKeys() {
+		keys = append(keys, key.Interface())
+	}
+	return keys
+}
+
 func mapWriteHandler(tagProvider TagProvider) WriteHandler {
 	return WriteHandler{
 		Tag: func(obj interface{}) string {
-			mapAsValue := reflect.ValueOf(obj)
-			keys := mapAsValue.MapKeys()
-
-			if stringableKeys(keys, tagProvider) {
+			if stringableKeys(mapKeys(obj), tagProvider) {
 				return "map"
 			} else {
 				return "cmap"
@@ -95,7 +107,7 @@ func mapWriteHandler(tagProvider TagProvider) WriteHandler {
 			mapAsValue := reflect.ValueOf(obj)
 			keys := mapAsValue.MapKeys()
 
-			if stringableKeys(keys, tagProvider) {
+			if stringableKeys(mapKeys(obj), tagProvider) {
 				entries, _ := mapToMapEntries(obj)
 				return entries
 			} else {
@@ -104,9 +116,91 @@ func mapWriteHandler(tagProvider TagProvider) WriteHandler {
 					list = append(list, key.Interface())
 					list = append(list, mapAsValue.MapIndex(key).Interface())
 				}
-				tv := TaggedValue{Tag: "array", Rep: list}
-				return tv
+				return list
+			}
+		},
+	}
+}
+
+// transitMapWriteHandler writes a Map in the order of its entries
+func transitMapWriteHandler(tagProvider TagProvider) WriteHandler {
+	return WriteHandler{
+		Name: "Map Write Handler",
+		Tag: func(obj interface{}) string {
+			if stringableKeys(obj.(Map).Keys(), tagProvider) {
+				return "map"
+			} else {
+				return "cmap"
+			}
+		},
+		Rep: func(obj interface{}) interface{} {
+			m := obj.(Map)
+			if stringableKeys(m.Keys(), tagProvider) {
+				return mapEntries(m.entries)
+			} else {
+				var list []interface{}
+				for _, entry := range m.entries {
+					list = append(list, entry.key, entry.value)
+				}
+				return list
+			}
+		},
+	}
+}
+
+// structWriteHandler writes any struct as a map of its exported fields, see structField
+func structWriteHandler() WriteHandler {
+	return WriteHandler{
+		Name: "Struct Write Handler",
+		Tag:  func(obj interface{}) string { return "map" },
+		Rep: func(obj interface{}) interface{} {
+			return structEntries(obj)
+		},
+	}
+}
+
+// convertingWriteHandler applies handler to values converted to baseType, like
+// an int8 or a named string type
+func convertingWriteHandler(handler WriteHandler, baseType reflect.Type) WriteHandler {
+	convert := func(obj interface{}) interface{} {
+		return reflect.ValueOf(obj).Convert(baseType).Interface()
+	}
+	return WriteHandler{
+		Name: handler.Name,
+		Tag: func(obj interface{}) string {
+			return handler.Tag(convert(obj))
+		},
+		Rep: func(obj interface{}) interface{} {
+			return handler.Rep(convert(obj))
+		},
+		StringRep: func(obj interface{}) *string {
+			if handler.StringRep == nil {
+				return nil
 			}
+			return handler.StringRep(convert(obj))
+		},
+	}
+}
+
+// pointerWriteHandler applies the handler of the value a pointer points to. Nil
+// pointers do not get here, lookupHandler writes them as null.
+func pointerWriteHandler(handler WriteHandler) WriteHandler {
+	elem := func(obj interface{}) interface{} {
+		return reflect.ValueOf(obj).Elem().Interface()
+	}
+	return WriteHandler{
+		Name: handler.Name,
+		Tag: func(obj interface{}) string {
+			return handler.Tag(elem(obj))
+		},
+		Rep: func(obj interface{}) interface{} {
+			return handler.Rep(elem(obj))
+		},
+		StringRep: func(obj interface{}) *string {
+			if handler.StringRep == nil {
+				return nil
+			}
+			return handler.StringRep(elem(obj))
 		},
 	}
 }
@@ -116,7 +210,17 @@ func setWriteHandler() WriteHandler {
 		Name: "Set Write Handler",
 		Tag:  func(obj interface{}) string { return "set" },
 		Rep: func(obj interface{}) interface{} {
-			return TaggedValue{Tag: "array", Rep: obj}
+			return obj.(Set).Items()
+		},
+	}
+}
+
+func listWriteHandler() WriteHandler {
+	return WriteHandler{
+		Name: "List Write Handler",
+		Tag:  func(obj interface{}) string { return "list" },
+		Rep: func(obj interface{}) interface{} {
+			return []interface{}(obj.(List))
 		},
 	}
 }
@@ -171,7 +275,7 @@ func floatWriteHandler() WriteHandler {
 			}
 		},
 		StringRep: func(obj interface{}) *string {
-			str := strconv.FormatFloat(obj.(float64), 'f', -1, 64)
+			str := strconv.FormatFloat(interfaceToFloat64(obj), 'f', -1, 64)
 			return &str
 		},
 	}
@@ -181,6 +285,19 @@ func doubleWriteHandler() WriteHandler {
 	return floatWriteHandler()
 }
 
+func interfaceToInt64(obj interface{}) (int64, bool) {
+	switch i := obj.(type) {
+	case int32:
+		return int64(i), true
+	case int:
+		return int64(i), true
+	case int64:
+		return i, true
+	default:
+		return 0, false
+	}
+}
+
 func integerWriteHandler() WriteHandler {
 	return WriteHandler{
 		Name: "Integer Write Handler",
@@ -195,6 +312,100 @@ func integerWriteHandler() WriteHandler {
 	}
 }
 
+// unsignedIntegerWriteHandler writes an uint64 as integer, or as big integer if it
+// does not fit in an int64
+func unsignedIntegerWriteHandler() WriteHandler {
+	return WriteHandler{
+		Name: "Unsigned Integer Write Handler",
+		Tag: func(obj interface{}) string {
+			if obj.(uint64) > math.MaxInt64 {
+				return "n"
+			}
+			return "i"
+		},
+		Rep: func(obj interface{}) interface{} {
+			u := obj.(uint64)
+			if u > math.MaxInt64 {
+				return strconv.FormatUint(u, 10)
+			}
+			return int64(u)
+		},
+		StringRep: func(obj interface{}) *string {
+			str := strconv.FormatUint(obj.(uint64), 10)
+			return &str
+		},
+	}
+}
+
+// bigIntegerWriteHandler writes a *big.Int, or null for a nil *big.Int
+func bigIntegerWriteHandler() WriteHandler {
+	return WriteHandler{
+		Name: "Big Integer Write Handler",
+		Tag: func(obj interface{}) string {
+			if obj.(*big.Int) == nil {
+				return "_"
+			}
+			return "n"
+		},
+		Rep: func(obj interface{}) interface{} {
+			return obj.(*big.Int).String()
+		},
+		StringRep: func(obj interface{}) *string {
+			str := obj.(*big.Int).String()
+			return &str
+		},
+	}
+}
+
+func bigDecimalWriteHandler() WriteHandler {
+	return WriteHandler{
+		Name: "Big Decimal Write Handler",
+		Tag:  func(obj interface{}) string { return "f" },
+		Rep: func(obj interface{}) interface{} {
+			return obj.(BigDecimal).String()
+		},
+		StringRep: func(obj interface{}) *string {
+			str := obj.(BigDecimal).String()
+			return &str
+		},
+	}
+}
+
+// bigFloatWriteHandler writes a *big.Float as a ~f decimal, or as a ~z special
+// number when it is infinite, like a float64
+func bigFloatWriteHandler() WriteHandler {
+	rep := func(obj interface{}) string {
+		f := obj.(*big.Float)
+		if f.IsInf() {
+			if f.Sign() < 0 {
+				return "-INF"
+			}
+			return "INF"
+		}
+		return f.Text('g', -1)
+	}
+	return WriteHandler{
+		Name: "Big Float Write Handler",
+		Tag: func(obj interface{}) string {
+			f := obj.(*big.Float)
+			if f == nil {
+				return "_"
+			}
+			if f.IsInf() {
+				return "z"
+			}
+			return "f"
+		},
+		Rep: func(obj interface{}) interface{} {
+			return rep(obj)
+		},
+		StringRep: func(obj interface{}) *string {
+			str := rep(obj)
+			return &str
+		},
+	}
+}
+
 func quoteWriteHandler() WriteHandler {
 	return WriteHandler{
 		Name: "Quote Write Handler",
@@ -209,16 +420,25 @@ func quoteWriteHandler() WriteHandler {
 	}
 }
 
+// ratioWriteHandler writes a *big.Rat (or big.Rat) as its numerator and denominator,
+// which are big integers so they keep their full precision
 func ratioWriteHandler() WriteHandler {
 	return WriteHandler{
 		Name: "Rational Write Handler",
-		Tag:  func(obj interface{}) string { return "ratio" },
+		Tag: func(obj interface{}) string {
+			if r, ok := obj.(*big.Rat); ok && r == nil {
+				return "_"
+			}
+			return "ratio"
+		},
 		Rep: func(obj interface{}) interface{} {
-			rational := obj.(big.Rat)
-			var list []int64
-			list = append(list, rational.Num().Int64())
-			list = append(list, rational.Denom().Int64())
-			return list
+			var rational *big.Rat
+			if r, ok := obj.(big.Rat); ok {
+				rational = &r
+			} else {
+				rational = obj.(*big.Rat)
+			}
+			return []interface{}{rational.Num(), rational.Denom()}
 		},
 	}
 }
@@ -240,29 +460,37 @@ func taggedValueWriteHandler() WriteHandler {
 	}
 }
 
+// verboseTimeFormat is the ISO 8601 format, with millisecond precision, of ~t timestamps
+const verboseTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// unixMillis returns the milliseconds since the epoch of t. Unlike t.UnixNano it does
+// not overflow for years before 1678 or after 2262.
+func unixMillis(t time.Time) int64 {
+	return t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+}
+
 func timeWriteHandler() WriteHandler {
 	return WriteHandler{
 		Name: "Time Write Handler",
 		Tag:  func(obj interface{}) string { return "m" },
 		Rep: func(obj interface{}) interface{} {
-			t := obj.(time.Time)
-			utcTime := t.UTC()
-			utcTimeNano := utcTime.UnixNano()
-			return utcTimeNano / int64(time.Millisecond)
+			return unixMillis(obj.(time.Time))
 		},
 		StringRep: func(obj interface{}) *string {
-			t := obj.(time.Time)
-			utcTime := t.UTC()
-			utcTimeNano := utcTime.UnixNano()
-			str := fmt.Sprintf("%d", utcTimeNano/int64(time.Millisecond))
+			str := strconv.FormatInt(unixMillis(obj.(time.Time)), 10)
 			return &str
 		},
 		VerboseHandler: &WriteHandler{
-			Tag: func(obj interface{}) string { return "t" },
+			Name: "Verbose Time Write Handler",
+			Tag:  func(obj interface{}) string { return "t" },
 			Rep: func(obj interface{}) interface{} {
 				t := obj.(time.Time)
-				utcTime := t.UTC()
-				return fmt.Sprintf("%v", utcTime)
+				return t.UTC().Format(verboseTimeFormat)
+			},
+			StringRep: func(obj interface{}) *string {
+				t := obj.(time.Time)
+				str := t.UTC().Format(verboseTimeFormat)
+				return &str
 			},
 		},
 	}
@@ -293,40 +521,23 @@ func runeWriteHandler() WriteHandler {
 	}
 }
 
-func read_int64(data []byte) (ret int64) {
-	buf := bytes.NewBuffer(data)
-	binary.Read(buf, binary.LittleEndian, &ret)
-	return
-}
-
-func uuidWriteHandler() WriteHandler {
-	return WriteHandler{
-		Name: "UUID Write Handler",
-		Tag:  func(obj interface{}) string { return "u" },
-		Rep: func(obj interface{}) interface{} {
-			uuid, _ := obj.(uuid.UUID)
-			bytes := uuid.Bytes()
-
-			var list []int64
-			list = append(list, read_int64(bytes[0:7]))
-			list = append(list, read_int64(bytes[8:15]))
-			return list
-		},
-		StringRep: func(obj interface{}) *string {
-			uuid, _ := obj.(uuid.UUID)
-			str := uuid.String()
-			return &str
-		},
-	}
-}
-
 func linkWriteHandler() WriteHandler {
 	return WriteHandler{
 		Name: "Link Write Handler",
 		Tag:  func(obj interface{}) string { return "link" },
 		Rep: func(obj interface{}) interface{} {
-			linkMap, _ := obj.(map[interface{}]interface{})
-			return linkMap
+			link := obj.(Link)
+			m := NewMap("href", TaggedValue{Tag: "r", Rep: link.Href}, "rel", link.Rel)
+			if link.Name != "" {
+				m = m.Assoc("name", link.Name)
+			}
+			if link.Prompt != "" {
+				m = m.Assoc("prompt", link.Prompt)
+			}
+			if link.Render != "" {
+				m = m.Assoc("render", link.Render)
+			}
+			return m
 		},
 	}
 }
@@ -6,9 +6,8 @@ import (
 	"math/big"
 	"net/url"
 	"reflect"
+	"sync"
 	"time"
-
-	"github.com/twinj/uuid"
 )
 
 type TransmitWriter interface {
@@ -46,13 +45,22 @@ type WriteHandlerMap map[reflect.Type]WriteHandler
 type transmitWriter struct {
 	emitter  Emitter
 	buffer   *bytes.Buffer
-	handlers WriteHandlerMap
+	handlers *writeHandlers
+	caching  bool
 }
 
 type JSONWriter struct {
 	transmitWriter
 }
 
+type JSONVerboseWriter struct {
+	transmitWriter
+}
+
+type MsgpackWriter struct {
+	transmitWriter
+}
+
 func (w transmitWriter) Buffer() *bytes.Buffer {
 	return w.buffer
 }
@@ -60,20 +68,26 @@ func (w transmitWriter) Buffer() *bytes.Buffer {
 func defaultWriteHandlers() WriteHandlerMap {
 	integerHandler := integerWriteHandler()
 	uriHandler := toStringWriteHandler("r")
+	uuidAdapter := TwinjUUIDAdapter()
 	handlers := WriteHandlerMap{
 		reflect.TypeOf(nil):                   nilWriteHandler(),
 		reflect.TypeOf(true):                  booleanWriteHandler(),
 		reflect.TypeOf(""):                    toStringWriteHandler("s"),
+		reflect.TypeOf(Keyword("")):           keywordWriteHandler(),
+		reflect.TypeOf(Symbol("")):            symbolWriteHandler(),
 		reflect.TypeOf(2):                     integerHandler,
 		reflect.TypeOf(int64(2)):              integerHandler,
 		reflect.TypeOf(int32(2)):              integerHandler,
+		reflect.TypeOf(uint64(2)):             unsignedIntegerWriteHandler(),
 		reflect.TypeOf(3.14159265359):         floatWriteHandler(),
 		reflect.TypeOf(float32(3.141)):        floatWriteHandler(),
-		reflect.TypeOf(big.NewInt(2)):         toStringWriteHandler("f"),
+		reflect.TypeOf(big.NewInt(2)):         bigIntegerWriteHandler(),
+		reflect.TypeOf(BigDecimal{}):          bigDecimalWriteHandler(),
+		reflect.TypeOf(big.NewFloat(2)):       bigFloatWriteHandler(),
 		reflect.TypeOf('c'):                   runeWriteHandler(),
 		reflect.TypeOf([]byte{}):              binaryWriteHandler(),
-		reflect.TypeOf(uuid.NewV4()):          uuidWriteHandler(),
 		reflect.TypeOf(&url.URL{}):            uriHandler,
+		uuidAdapter.Type:                      uuidAdapter.WriteHandler(),
 		reflect.TypeOf([]int{}):               arrayWriteHandler(),
 		reflect.TypeOf([]int32{}):             arrayWriteHandler(),
 		reflect.TypeOf([]int64{}):             arrayWriteHandler(),
@@ -85,14 +99,39 @@ func defaultWriteHandlers() WriteHandlerMap {
 		reflect.TypeOf([]map[string]string{}): arrayWriteHandler(),
 		reflect.TypeOf([]interface{}{}):       arrayWriteHandler(),
 		reflect.TypeOf(NewSet()):              setWriteHandler(),
+		reflect.TypeOf(List{}):                listWriteHandler(),
+		reflect.TypeOf(Link{}):                linkWriteHandler(),
 		reflect.TypeOf(time.Now()):            timeWriteHandler(),
 		reflect.TypeOf(big.Rat{}):             ratioWriteHandler(),
+		reflect.TypeOf(big.NewRat(1, 2)):      ratioWriteHandler(),
 		reflect.TypeOf(Quote{}):               quoteWriteHandler(),
 		reflect.TypeOf(TaggedValue{}):         taggedValueWriteHandler(),
 	}
 	return handlers
 }
 
+// defaultHandlers and defaultVerboseHandlers are shared by the writers without
+// custom handlers, so the handlers they resolve are kept from one write to the next
+var (
+	defaultHandlers        = newWriteHandlers(defaultWriteHandlers())
+	defaultVerboseHandlers = newWriteHandlers(defaultWriteHandlers().verboseHandlers())
+)
+
+// writerHandlers returns the handlers of a writer with the custom handlers
+func writerHandlers(customHandlers WriteHandlerMap, verbose bool) *writeHandlers {
+	if len(customHandlers) == 0 {
+		if verbose {
+			return defaultVerboseHandlers
+		}
+		return defaultHandlers
+	}
+	handlers := mergeWriteHandlers(customHandlers)
+	if verbose {
+		handlers = handlers.verboseHandlers()
+	}
+	return newWriteHandlers(handlers)
+}
+
 func (m WriteHandlerMap) GetTag(obj interface{}) string {
 	handler, err := m.lookupHandler(obj)
 	if err != nil {
@@ -101,40 +140,218 @@ func (m WriteHandlerMap) GetTag(obj interface{}) string {
 	return handler.Tag(obj)
 }
 
-func NewJSONWriter(buffer *bytes.Buffer) JSONWriter {
-	emitter := NewJsonEmitter(buffer, defaultWriteHandlers())
+// NewJSONWriter returns a writer of transit JSON. It accepts the caching,
+// canonical order and handler options, other options are ignored.
+func NewJSONWriter(buffer *bytes.Buffer, opts ...Option) JSONWriter {
+	return NewJSONWriterWithHandlers(buffer, WriteHandlerMap{}, opts...)
+}
+
+func NewJSONWriterWithHandlers(buffer *bytes.Buffer, customHandlers WriteHandlerMap, opts ...Option) JSONWriter {
+	o, handlers := newWriterOptions(customHandlers, opts, false)
+
+	emitter := newJsonEmitter(buffer, handlers)
+	emitter.setCanonicalOrder(o.canonicalOrder)
+	return JSONWriter{transmitWriter{buffer: buffer, emitter: emitter, handlers: handlers, caching: o.caching}}
+}
 
-	return JSONWriter{transmitWriter{buffer: buffer, emitter: emitter, handlers: defaultWriteHandlers()}}
+func NewJSONVerboseWriter(buffer *bytes.Buffer, opts ...Option) JSONVerboseWriter {
+	return NewJSONVerboseWriterWithHandlers(buffer, WriteHandlerMap{}, opts...)
 }
 
-func NewJSONWriterWithHandlers(buffer *bytes.Buffer, customHandlers WriteHandlerMap) JSONWriter {
+func NewJSONVerboseWriterWithHandlers(buffer *bytes.Buffer, customHandlers WriteHandlerMap, opts ...Option) JSONVerboseWriter {
+	o, handlers := newWriterOptions(customHandlers, opts, true)
+
+	emitter := newJsonVerboseEmitter(buffer, handlers)
+	emitter.setCanonicalOrder(o.canonicalOrder)
+	return JSONVerboseWriter{transmitWriter{buffer: buffer, emitter: emitter, handlers: handlers}}
+}
+
+func NewMsgpackWriter(buffer *bytes.Buffer, opts ...Option) MsgpackWriter {
+	return NewMsgpackWriterWithHandlers(buffer, WriteHandlerMap{}, opts...)
+}
+
+func NewMsgpackWriterWithHandlers(buffer *bytes.Buffer, customHandlers WriteHandlerMap, opts ...Option) MsgpackWriter {
+	o, handlers := newWriterOptions(customHandlers, opts, false)
+
+	emitter := newMsgpackEmitter(buffer, handlers)
+	emitter.setCanonicalOrder(o.canonicalOrder)
+	return MsgpackWriter{transmitWriter{buffer: buffer, emitter: emitter, handlers: handlers, caching: o.caching}}
+}
+
+// newWriterOptions returns the options of a writer, and its handlers: the default
+// handlers, the custom handlers and the handlers of the options
+func newWriterOptions(customHandlers WriteHandlerMap, opts []Option, verbose bool) (*options, *writeHandlers) {
+	o := newOptions(append([]Option{WithWriteHandlers(customHandlers)}, opts...))
+	return o, writerHandlers(o.writeHandlers, verbose)
+}
+
+func mergeWriteHandlers(customHandlers WriteHandlerMap) WriteHandlerMap {
 	handlers := defaultWriteHandlers()
 
 	for typ, handler := range customHandlers {
 		handlers[typ] = handler
 	}
+	return handlers
+}
 
-	emitter := NewJsonEmitter(buffer, handlers)
-	return JSONWriter{transmitWriter{buffer: buffer, emitter: emitter, handlers: handlers}}
+// verboseHandlers returns a copy of the map in which every handler that has a
+// VerboseHandler is replaced by it.
+func (m WriteHandlerMap) verboseHandlers() WriteHandlerMap {
+	handlers := make(WriteHandlerMap, len(m))
+	for typ, handler := range m {
+		if handler.VerboseHandler != nil {
+			handlers[typ] = *handler.VerboseHandler
+		} else {
+			handlers[typ] = handler
+		}
+	}
+	return handlers
 }
 
+// lookupHandler finds the handler for obj, see writeHandlers.lookupHandler
 func (m WriteHandlerMap) lookupHandler(obj interface{}) (WriteHandler, error) {
+	return newWriteHandlers(m).lookupHandler(obj)
+}
+
+// writeHandlers are the handlers of one or more writers. The handlers it resolves
+// for types without a registered handler are kept apart, so the handler map is
+// never changed, and writers in concurrent goroutines can share the handlers. A
+// writer itself must not be used by concurrent goroutines.
+type writeHandlers struct {
+	handlers WriteHandlerMap
+	resolved sync.Map
+}
+
+// newWriteHandlers returns the handlers of the handler map. The handler of Map looks
+// up the handlers of its keys, so it is resolved here instead of being registered;
+// a handler registered for Map still takes precedence.
+func newWriteHandlers(handlers WriteHandlerMap) *writeHandlers {
+	w := &writeHandlers{handlers: handlers}
+	w.resolved.Store(reflect.TypeOf(Map{}), transitMapWriteHandler(w))
+	return w
+}
+
+func (w *writeHandlers) GetTag(obj interface{}) string {
+	handler, err := w.lookupHandler(obj)
+	if err != nil {
+		return ""
+	}
+	return handler.Tag(obj)
+}
+
+// lookupHandler finds the handler for obj. Nil pointers are written as null, values
+// implementing TransitMarshaler write themselves. Otherwise the handler registered for the type of obj is used,
+// or else the handler of the interface it implements, or else a handler based on
+// its kind. Handlers found for an interface or kind are remembered, so the next
+// lookup for the same type finds them directly.
+func (w *writeHandlers) lookupHandler(obj interface{}) (WriteHandler, error) {
+	if value := reflect.ValueOf(obj); value.Kind() == reflect.Ptr && value.IsNil() {
+		return nilWriteHandler(), nil
+	}
+
+	if marshaler, ok := obj.(TransitMarshaler); ok {
+		tag, rep, err := marshaler.MarshalTransit()
+		if err != nil {
+			return WriteHandler{}, err
+		}
+		return marshalerWriteHandler(tag, rep), nil
+	}
+
 	objType := reflect.TypeOf(obj)
-	result, ok := m[objType]
-	if !ok {
-
-		// Maybe it is some kind of map
-		if objType.Kind() == reflect.Map {
-			return mapWriteHandler(m), nil
-		} else if objType.Kind() == reflect.Array {
-			return arrayWriteHandler(), nil
+	if handler, ok := w.handlers[objType]; ok {
+		return handler, nil
+	}
+	if handler, ok := w.resolved.Load(objType); ok {
+		return handler.(WriteHandler), nil
+	}
+	handler, err := w.resolveHandler(objType)
+	if err != nil {
+		return WriteHandler{}, err
+	}
+	w.resolved.Store(objType, handler)
+	return handler, nil
+}
+
+// kindBaseTypes are the types that values of a kind are converted to, to be
+// written by the handler of that type
+var kindBaseTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int64(0)),
+	reflect.Int8:    reflect.TypeOf(int64(0)),
+	reflect.Int16:   reflect.TypeOf(int64(0)),
+	reflect.Int32:   reflect.TypeOf(int64(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint64(0)),
+	reflect.Uint8:   reflect.TypeOf(uint64(0)),
+	reflect.Uint16:  reflect.TypeOf(uint64(0)),
+	reflect.Uint32:  reflect.TypeOf(uint64(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+// resolveHandler finds the handler for a type that has no handler registered
+func (w *writeHandlers) resolveHandler(objType reflect.Type) (WriteHandler, error) {
+	if objType == nil {
+		return WriteHandler{}, fmt.Errorf("No handler found for nil")
+	}
+
+	var matches []reflect.Type
+	for typ := range w.handlers {
+		if typ != nil && typ.Kind() == reflect.Interface && objType.Implements(typ) {
+			matches = append(matches, typ)
+		}
+	}
+	if len(matches) == 1 {
+		return w.handlers[matches[0]], nil
+	} else if len(matches) > 1 {
+		return WriteHandler{}, fmt.Errorf("More than one handler found for type %+v, it implements %+v", objType, matches)
+	}
+
+	switch objType.Kind() {
+	case reflect.Map:
+		return mapWriteHandler(w), nil
+	case reflect.Array:
+		return arrayWriteHandler(), nil
+	case reflect.Slice:
+		bytesType := reflect.TypeOf([]byte{})
+		if handler, ok := w.handlers[bytesType]; ok && objType.ConvertibleTo(bytesType) {
+			return convertingWriteHandler(handler, bytesType), nil
+		}
+		return arrayWriteHandler(), nil
+	case reflect.Struct:
+		return structWriteHandler(), nil
+	case reflect.Ptr:
+		handler, ok := w.handlers[objType.Elem()]
+		if !ok {
+			var err error
+			handler, err = w.resolveHandler(objType.Elem())
+			if err != nil {
+				return WriteHandler{}, err
+			}
 		}
+		return pointerWriteHandler(handler), nil
+	}
 
-		return WriteHandler{}, fmt.Errorf("No handler found for type %+v", objType)
+	if baseType, ok := kindBaseTypes[objType.Kind()]; ok {
+		if handler, ok := w.handlers[baseType]; ok {
+			return convertingWriteHandler(handler, baseType), nil
+		}
 	}
-	return result, nil
+
+	return WriteHandler{}, fmt.Errorf("No handler found for type %+v", objType)
 }
 
 func (w JSONWriter) Write(obj interface{}) error {
-	return w.emitter.emit(obj, false, NewWriteCache(true))
+	return w.emitter.emit(obj, false, NewWriteCache(w.caching))
+}
+
+// Write writes obj without caching, so the output stays readable
+func (w JSONVerboseWriter) Write(obj interface{}) error {
+	return w.emitter.emit(obj, false, NewWriteCache(false))
+}
+
+func (w MsgpackWriter) Write(obj interface{}) error {
+	return w.emitter.emit(obj, false, NewWriteCache(w.caching))
 }
@@ -5,6 +5,8 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"math"
+	"math/big"
 	"net/url"
 	"reflect"
 	"time"
@@ -57,6 +59,44 @@ var _ = Describe("JSON Writer", func() {
 		Expect(result).To(Equal("[\"~#'\",\"~i9007199254740999\"]"))
 	})
 
+	It("marshals integers within the range of JavaScript numbers as numbers", func() {
+		result := write(writer, []int64{55, 9007199254740991, -9007199254740991})
+		Expect(result).To(Equal("[55,9007199254740991,-9007199254740991]"))
+	})
+
+	It("marshals 64-bit integers outside the range of JavaScript numbers as strings", func() {
+		result := write(writer, []interface{}{int64(9007199254740992), int64(math.MinInt64), uint64(math.MaxInt64)})
+		Expect(result).To(Equal("[\"~i9007199254740992\",\"~i-9223372036854775808\",\"~i9223372036854775807\"]"))
+	})
+
+	It("marshals unsigned integers that do not fit in an int64 as big integers", func() {
+		result := write(writer, uint64(math.MaxUint64))
+		Expect(result).To(Equal("[\"~#'\",\"~n18446744073709551615\"]"))
+	})
+
+	It("marshals a *big.Int", func() {
+		n, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+		result := write(writer, map[string]*big.Int{"n": n})
+		Expect(result).To(Equal("[\"^ \",\"n\",\"~n-123456789012345678901234567890\"]"))
+	})
+
+	It("marshals a nil *big.Int as null", func() {
+		var n *big.Int
+		result := write(writer, map[string]*big.Int{"n": n})
+		Expect(result).To(Equal("[\"^ \",\"n\",null]"))
+	})
+
+	It("marshals a nil *big.Rat as null", func() {
+		var r *big.Rat
+		result := write(writer, map[string]*big.Rat{"r": r})
+		Expect(result).To(Equal("[\"^ \",\"r\",null]"))
+	})
+
+	It("marshals ratios as tagged big integers", func() {
+		result := write(writer, []interface{}{big.NewRat(1, 3), *big.NewRat(-4, 6)})
+		Expect(result).To(Equal("[[\"~#ratio\",[\"~n1\",\"~n3\"]],[\"^0\",[\"~n-2\",\"~n3\"]]]"))
+	})
+
 	It("marshals a float", func() {
 		pi := 3.14159265359
 		result := write(writer, pi)
@@ -104,6 +144,22 @@ var _ = Describe("JSON Writer", func() {
 		Expect(result).To(Equal(fmt.Sprintf("[\"~#'\",\"%s\"]", fmt.Sprintf("~c%s", string(char)))))
 	})
 
+	It("marshals keywords and symbols", func() {
+		result := write(writer, []interface{}{Keyword("name"), Symbol("user/name")})
+		Expect(result).To(Equal("[\"~:name\",\"~$user/name\"]"))
+	})
+
+	It("marshals and caches keyword keys", func() {
+		m := map[Keyword]int{"name": 1}
+		result := write(writer, []interface{}{m, m, Keyword("name")})
+		Expect(result).To(Equal("[[\"^ \",\"~:name\",1],[\"^ \",\"^0\",1],\"^0\"]"))
+	})
+
+	It("returns the error of an element it cannot marshal", func() {
+		err := writer.Write([]interface{}{1, make(chan int)})
+		Expect(err).NotTo(BeNil())
+	})
+
 	It("marshals a UUID", func() {
 		uuid, err := uuid.Parse("dda5a83f-8f9d-4194-ae88-5745c8ca94a7")
 		Expect(err).To(BeNil())
@@ -191,6 +247,23 @@ var _ = Describe("JSON Writer", func() {
 		Expect(result).To(MatchRegexp("\\[13,14,15\\],\"~bd29ybGQ=\""))
 	})
 
+	It("marshals maps with composite keys as a cmap of keys and values, and scalar keys as strings", func() {
+		Expect(write(writer, map[*big.Int]int{big.NewInt(1): 2})).To(Equal("[\"^ \",\"~n1\",2]"))
+		buffer.Reset()
+		Expect(write(writer, NewMap(NewSetFrom([]interface{}{1}), "set"))).To(Equal("[\"~#cmap\",[[\"~#set\",[1]],\"set\"]]"))
+		buffer.Reset()
+		Expect(write(writer, NewMap(TaggedValue{Tag: "point", Rep: []int{1, 2}}, "point"))).To(Equal("[\"~#cmap\",[[\"~#point\",[1,2]],\"point\"]]"))
+		buffer.Reset()
+		Expect(write(writer, NewMap(NewMap("a", 1), "map"))).To(Equal("[\"~#cmap\",[[\"^ \",\"a\",1],\"map\"]]"))
+	})
+
+	It("accepts options", func() {
+		var buffer bytes.Buffer
+		w := NewJSONWriter(&buffer, WithCaching(false), WithCanonicalOrder(true))
+		m := map[string]int{"name": 1, "age": 2}
+		Expect(write(w, []interface{}{m, m})).To(Equal("[[\"^ \",\"age\",2,\"name\",1],[\"^ \",\"age\",2,\"name\",1]]"))
+	})
+
 	It("allows custom writers", func() {
 		type Point struct {
 			x float32